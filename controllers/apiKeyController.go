@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyBody is the POST payload. Without expires_at the key does not
+// expire.
+type apiKeyBody struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required" enums:"read,write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// createdAPIKey is the key as returned once, on creation.
+type createdAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// apiKeyUser is the caller, provided the caller logged in: API keys can't
+// be used to manage API keys.
+func apiKeyUser(c *gin.Context) (*models.User, bool) {
+	if currentAPIKey(c) != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrAPIKeyForbidden.Error()})
+		return nil, false
+	}
+
+	return currentUser(c), true
+}
+
+// APIKeyIndex godoc
+// @Summary List API keys
+// @Description Получение API-ключей текущего пользователя, от новых к старым; сами ключи не возвращаются
+// @Tags api-keys
+// @Produce  json
+// @Success 200 {array} models.APIKey "API keys"
+// @Failure 403 {object} map[string]string "Called with an API key"
+// @Router /api-keys [get]
+func APIKeyIndex(c *gin.Context) {
+	user, ok := apiKeyUser(c)
+	if !ok {
+		return
+	}
+
+	keys, err := newAuthService().ListAPIKeys(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": keys,
+	})
+}
+
+// APIKeyCreate godoc
+// @Summary Create an API key
+// @Description Создание API-ключа для скриптов: name, scopes (read — чтение, write — изменения) и необязательный expires_at
+// @Description Ключ возвращается в поле key только в этом ответе и передается в заголовке Authorization: Bearer <key>
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param key body apiKeyBody true "Create API Key"
+// @Success 201 {object} createdAPIKey "Successfully created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Called with an API key"
+// @Router /api-keys [post]
+func APIKeyCreate(c *gin.Context) {
+	user, ok := apiKeyUser(c)
+	if !ok {
+		return
+	}
+
+	var body apiKeyBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, name and scopes are required"})
+		return
+	}
+
+	key, secret, err := newAuthService().CreateAPIKey(user, body.Name, body.Scopes, body.ExpiresAt)
+	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": createdAPIKey{APIKey: *key, Key: secret},
+	})
+}
+
+// APIKeyDelete godoc
+// @Summary Revoke an API key
+// @Description Отзыв API-ключа, он сразу перестает действовать
+// @Tags api-keys
+// @Param id path int true "API Key ID"
+// @Success 204 {string} string "Successfully revoked"
+// @Failure 403 {object} map[string]string "Called with an API key"
+// @Failure 404 {object} map[string]string "API key not found"
+// @Router /api-keys/{id} [delete]
+func APIKeyDelete(c *gin.Context) {
+	user, ok := apiKeyUser(c)
+	if !ok {
+		return
+	}
+
+	if err := newAuthService().RevokeAPIKey(user, c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key doesn't exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/services"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is how much a multipart body may exceed the file it
+// carries: boundaries, part headers and the file name.
+const multipartOverhead = 64 << 10
+
+func newAttachmentService() *services.AttachmentService {
+	return services.NewAttachmentService(initializers.AttachmentRepository, initializers.BlobStore, initializers.AttachmentMaxSize)
+}
+
+// AttachmentIndex godoc
+// @Summary List attachments
+// @Description Получение вложений todo, от старых к новым
+// @Tags attachments
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Success 200 {array} models.Attachment "Attachments"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /todo/{id}/attachments [get]
+func AttachmentIndex(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := newAttachmentService().ListAttachments(todo)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachments": attachments,
+	})
+}
+
+// AttachmentCreate godoc
+// @Summary Upload an attachment
+// @Description Загрузка файла к todo в поле file (multipart/form-data).
+// @Description Размер ограничен ATTACHMENT_MAX_SIZE (по умолчанию 10 МБ), тип содержимого определяется по самому файлу
+// @Tags attachments
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} models.Attachment "Successfully uploaded"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 413 {object} map[string]string "File is too large"
+// @Router /todo/{id}/attachments [post]
+func AttachmentCreate(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	attachmentService := newAttachmentService()
+
+	// Oversized uploads are cut off while the body is read instead of being
+	// spooled to disk first.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, attachmentService.MaxSize()+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondAttachmentError(c, services.ErrAttachmentTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, file is required"})
+		return
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, file is required"})
+		return
+	}
+	defer content.Close()
+
+	attachment, err := attachmentService.Upload(todo, file.Filename, content, file.Size)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"attachment": attachment,
+	})
+}
+
+// AttachmentShow godoc
+// @Summary Show an attachment
+// @Description Получение описания вложения todo по id
+// @Tags attachments
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {object} models.Attachment "Attachment details"
+// @Failure 404 {object} map[string]string "Todo or attachment not found"
+// @Router /todo/{id}/attachments/{attachmentId} [get]
+func AttachmentShow(c *gin.Context) {
+	attachment, ok := findAttachment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachment": attachment,
+	})
+}
+
+// AttachmentContent godoc
+// @Summary Download an attachment
+// @Description Скачивание содержимого вложения, файл передается потоком
+// @Tags attachments
+// @Produce  octet-stream
+// @Param id path int true "Todo ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {file} file "Attachment content"
+// @Failure 404 {object} map[string]string "Todo or attachment not found"
+// @Router /todo/{id}/attachments/{attachmentId}/content [get]
+func AttachmentContent(c *gin.Context) {
+	attachment, ok := findAttachment(c)
+	if !ok {
+		return
+	}
+
+	content, err := newAttachmentService().Open(attachment)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// AttachmentDelete godoc
+// @Summary Delete an attachment
+// @Description Удаление вложения todo вместе с содержимым
+// @Tags attachments
+// @Param id path int true "Todo ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 204 {string} string "Successfully deleted"
+// @Failure 404 {object} map[string]string "Todo or attachment not found"
+// @Router /todo/{id}/attachments/{attachmentId} [delete]
+func AttachmentDelete(c *gin.Context) {
+	attachment, ok := findAttachment(c)
+	if !ok {
+		return
+	}
+
+	if err := newAttachmentService().DeleteAttachment(attachment); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func findAttachment(c *gin.Context) (*models.Attachment, bool) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return nil, false
+	}
+
+	attachment, err := newAttachmentService().FindAttachment(todo, c.Param("attachmentId"))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+func respondAttachmentError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment doesn't exist"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
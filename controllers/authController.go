@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userKey and apiKeyKey are where Authenticate keeps the caller, and the
+// API key it used if any, in the gin context.
+const (
+	userKey   = "user"
+	apiKeyKey = "apiKey"
+)
+
+type registerBody struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// loginBody is the login payload; login is a username or an email.
+type loginBody struct {
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type refreshBody struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// tokenResponse is returned by login and refresh. The access token goes in
+// "Authorization: Bearer <token>" until expires_at; the refresh token can
+// be traded for new tokens once, until refresh_expires_at.
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type" example:"Bearer"`
+	ExpiresAt        time.Time   `json:"expires_at"`
+	RefreshToken     string      `json:"refresh_token"`
+	RefreshExpiresAt time.Time   `json:"refresh_expires_at"`
+	User             models.User `json:"user"`
+}
+
+func newAuthService() *services.AuthService {
+	return services.NewAuthService(initializers.UserRepository, initializers.Auth)
+}
+
+func respondTokens(c *gin.Context, tokens *services.Tokens) {
+	c.JSON(http.StatusOK, tokenResponse{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		User:             *tokens.User,
+	})
+}
+
+// respondUnauthorized is how every 401 looks: {"error": "..."} and a
+// WWW-Authenticate challenge, with error="invalid_token" if a token was
+// sent but not accepted.
+func respondUnauthorized(c *gin.Context, err error) {
+	challenge := "Bearer"
+	if !errors.Is(err, services.ErrUnauthenticated) && !errors.Is(err, services.ErrInvalidCredentials) {
+		challenge = `Bearer error="invalid_token"`
+	}
+
+	c.Header("WWW-Authenticate", challenge)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
+
+// ownTodos is the todo repository as the caller sees it.
+func ownTodos(c *gin.Context) repositories.TodoRepository {
+	return repositories.NewOwnedTodoRepository(initializers.TodoRepository, currentUser(c).ID).
+		WithShares(initializers.ShareRepository).
+		WithProjects(initializers.ProjectRepository)
+}
+
+// ownProjects is the caller's view of the projects: their own and the ones
+// shared with them.
+func ownProjects(c *gin.Context) repositories.ProjectRepository {
+	return repositories.NewOwnedProjectRepository(initializers.ProjectRepository, currentUser(c).ID).
+		WithShares(initializers.ShareRepository)
+}
+
+// currentUser is the caller. Only handlers behind Authenticate may use it.
+func currentUser(c *gin.Context) *models.User {
+	return c.MustGet(userKey).(*models.User)
+}
+
+// currentAPIKey is the API key the caller used, nil for a logged in user.
+func currentAPIKey(c *gin.Context) *models.APIKey {
+	key, _ := c.Get(apiKeyKey)
+	apiKey, _ := key.(*models.APIKey)
+	return apiKey
+}
+
+// Authenticate lets through only requests with a valid access token or API
+// key in an "Authorization: Bearer <token>" header, and makes the caller
+// available to the handlers after it. Access tokens are checked by signature
+// alone; API keys also have to have a scope that covers the request.
+func Authenticate(c *gin.Context) {
+	scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		token = ""
+	}
+
+	identity, err := newAuthService().Authenticate(strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, services.ErrUnauthenticated) || errors.Is(err, services.ErrInvalidToken) ||
+			errors.Is(err, services.ErrTokenExpired) || errors.Is(err, services.ErrInvalidAPIKey) {
+			respondUnauthorized(c, err)
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		return
+	}
+
+	if key := identity.APIKey; key != nil {
+		if !key.Allows(c.Request.Method) {
+			scope := models.ScopeFor(c.Request.Method)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("API key doesn't have the %s scope", scope)})
+			return
+		}
+		c.Set(apiKeyKey, key)
+	}
+
+	c.Set(userKey, identity.User)
+	c.Next()
+}
+
+// Require lets through only callers whose role has permission; the others
+// get a 403 before any handler runs. The role comes with the access token or
+// the API key's user, so the check doesn't touch the database.
+func Require(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !currentUser(c).Role.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Your role doesn't have the %s permission", permission)})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuthRegister godoc
+// @Summary Register a user
+// @Description Регистрация пользователя
+// @Description username — от 3 до 50 символов: латинские буквы, цифры, точка, дефис и подчеркивание
+// @Description username и email приводятся к нижнему регистру и должны быть уникальны, пароль — от 8 символов и не длиннее 72 байт
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param user body registerBody true "Register User"
+// @Success 201 {object} models.User "Successfully registered"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Username or email already taken"
+// @Router /auth/register [post]
+func AuthRegister(c *gin.Context) {
+	var body registerBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, username, email and password are required"})
+		return
+	}
+
+	user, err := newAuthService().Register(body.Username, body.Email, body.Password)
+	if err != nil {
+		var validationErr *services.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+		case errors.Is(err, services.ErrDuplicateUser):
+			c.JSON(http.StatusConflict, gin.H{"error": "Username or email is already taken"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"user": user,
+	})
+}
+
+// AuthLogin godoc
+// @Summary Log in
+// @Description Вход по username или email и паролю
+// @Description Возвращает access_token (JWT), который передается в заголовке Authorization: Bearer <token> до expires_at,
+// @Description и refresh_token для получения новых токенов через /auth/refresh
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param credentials body loginBody true "Credentials"
+// @Success 200 {object} tokenResponse "Tokens"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Invalid login or password"
+// @Router /auth/login [post]
+func AuthLogin(c *gin.Context) {
+	var body loginBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, login and password are required"})
+		return
+	}
+
+	tokens, err := newAuthService().Login(body.Login, body.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			respondUnauthorized(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	respondTokens(c, tokens)
+}
+
+// AuthRefresh godoc
+// @Summary Refresh tokens
+// @Description Обмен refresh_token на новые access_token и refresh_token, старый refresh_token перестает действовать
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param token body refreshBody true "Refresh token"
+// @Success 200 {object} tokenResponse "Tokens"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Invalid refresh token"
+// @Router /auth/refresh [post]
+func AuthRefresh(c *gin.Context) {
+	var body refreshBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, refresh_token is required"})
+		return
+	}
+
+	tokens, err := newAuthService().Refresh(body.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			respondUnauthorized(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh tokens"})
+		return
+	}
+
+	respondTokens(c, tokens)
+}
+
+// AuthLogout godoc
+// @Summary Log out
+// @Description Отзыв refresh_token: сессию больше нельзя продлить, выданные access_token действуют до истечения срока
+// @Tags auth
+// @Accept  json
+// @Param token body refreshBody true "Refresh token"
+// @Success 204 {string} string "Successfully logged out"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Invalid refresh token"
+// @Router /auth/logout [post]
+func AuthLogout(c *gin.Context) {
+	var body refreshBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, refresh_token is required"})
+		return
+	}
+
+	if err := newAuthService().Logout(body.RefreshToken); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			respondUnauthorized(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// commentBody is the POST payload; body is markdown. The author is the
+// caller.
+type commentBody struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// editCommentBody is the PUT payload. Only the body of a comment changes.
+type editCommentBody struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type commentPage struct {
+	Comments []models.Comment `json:"comments"`
+	Total    int64            `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+func newCommentService(c *gin.Context) *services.CommentService {
+	return services.NewCommentService(initializers.CommentRepository, ownTodos(c), currentUser(c).ID)
+}
+
+// CommentIndex godoc
+// @Summary List comments
+// @Description Получение комментариев todo страницами по limit/offset, от старых к новым
+// @Tags comments
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of comments to skip"
+// @Success 200 {object} commentPage "Page of comments"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /todo/{id}/comments [get]
+func CommentIndex(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	pageRequest, err := bindPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := newCommentService(c).ListComments(todo, pageRequest)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commentPage{
+		Comments: page.Comments,
+		Total:    page.Total,
+		Limit:    page.Limit,
+		Offset:   page.Offset,
+	})
+}
+
+// CommentCreate godoc
+// @Summary Create a comment
+// @Description Добавление комментария к todo: body (markdown, до 10000 символов) обязателен, author — имя текущего пользователя
+// @Tags comments
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param comment body commentBody true "Create Comment"
+// @Success 201 {object} models.Comment "Successfully created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /todo/{id}/comments [post]
+func CommentCreate(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	var body commentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, body is required"})
+		return
+	}
+
+	comment, err := newCommentService(c).CreateComment(todo, currentUser(c).Username, body.Body)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"comment": comment,
+	})
+}
+
+// CommentShow godoc
+// @Summary Show a comment
+// @Description Получение комментария todo по id
+// @Tags comments
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param commentId path int true "Comment ID"
+// @Success 200 {object} models.Comment "Comment details"
+// @Failure 404 {object} map[string]string "Todo or comment not found"
+// @Router /todo/{id}/comments/{commentId} [get]
+func CommentShow(c *gin.Context) {
+	_, comment, ok := findComment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comment": comment,
+	})
+}
+
+// CommentUpdate godoc
+// @Summary Edit a comment
+// @Description Изменение текста комментария, автор не меняется. Менять комментарий могут его автор и владельцы todo
+// @Tags comments
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param commentId path int true "Comment ID"
+// @Param comment body editCommentBody true "Edit Comment"
+// @Success 200 {object} models.Comment "Successfully updated"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Neither the author nor the owner of the todo"
+// @Failure 404 {object} map[string]string "Todo or comment not found"
+// @Router /todo/{id}/comments/{commentId} [put]
+func CommentUpdate(c *gin.Context) {
+	todo, comment, ok := findComment(c)
+	if !ok {
+		return
+	}
+
+	var body editCommentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, body is required"})
+		return
+	}
+
+	if err := newCommentService(c).EditComment(todo, comment, body.Body); err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comment": comment,
+	})
+}
+
+// CommentDelete godoc
+// @Summary Delete a comment
+// @Description Удаление комментария todo его автором или владельцами todo
+// @Tags comments
+// @Param id path int true "Todo ID"
+// @Param commentId path int true "Comment ID"
+// @Success 204 {string} string "Successfully deleted"
+// @Failure 403 {object} map[string]string "Neither the author nor the owner of the todo"
+// @Failure 404 {object} map[string]string "Todo or comment not found"
+// @Router /todo/{id}/comments/{commentId} [delete]
+func CommentDelete(c *gin.Context) {
+	todo, comment, ok := findComment(c)
+	if !ok {
+		return
+	}
+
+	if err := newCommentService(c).DeleteComment(todo, comment); err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// findComment looks up the comment of the request and the todo it is on.
+func findComment(c *gin.Context) (*models.ToDo, *models.Comment, bool) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	comment, err := newCommentService(c).FindComment(todo, c.Param("commentId"))
+	if err != nil {
+		respondCommentError(c, err)
+		return nil, nil, false
+	}
+
+	return todo, comment, true
+}
+
+func respondCommentError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, services.ErrInvalidPage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotCommentAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment doesn't exist"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
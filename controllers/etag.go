@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func etag(todo *models.ToDo) string {
+	return fmt.Sprintf(`"%d-%d"`, todo.ID, todo.Version)
+}
+
+func setETag(c *gin.Context, todo *models.ToDo) {
+	c.Header("ETag", etag(todo))
+}
+
+// matchesETag reports whether header, an If-Match or If-None-Match list,
+// names the current representation of todo. Weak validators only count when
+// weak is set, as RFC 9110 requires for If-None-Match.
+func matchesETag(header string, todo *models.ToDo, weak bool) bool {
+	current := etag(todo)
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if tag == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkIfMatch answers 412 when the request carries an If-Match header that
+// does not match todo. It reports whether the handler may go on.
+func checkIfMatch(c *gin.Context, todo *models.ToDo) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || matchesETag(header, todo, false) {
+		return true
+	}
+
+	setETag(c, todo)
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "ToDo has been modified"})
+
+	return false
+}
+
+// notModified answers 304 when If-None-Match already names todo.
+func notModified(c *gin.Context, todo *models.ToDo) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" || !matchesETag(header, todo, true) {
+		return false
+	}
+
+	setETag(c, todo)
+	c.Status(http.StatusNotModified)
+
+	return true
+}
+
+// respondVersionConflict reports a write that lost a race: 412 when the
+// client made it conditional, 409 otherwise.
+func respondVersionConflict(c *gin.Context) {
+	if c.GetHeader("If-Match") != "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "ToDo has been modified"})
+		return
+	}
+
+	c.JSON(http.StatusConflict, gin.H{"error": "ToDo has been modified"})
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/filters"
+	"example/Studying/models"
+	"example/Studying/services"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindPage reads limit, offset, cursor and sort from the query string.
+func bindPage(c *gin.Context) (services.PageRequest, error) {
+	var page services.PageRequest
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return page, services.ErrInvalidPage
+		}
+		page.Limit = n
+	}
+
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return page, services.ErrInvalidPage
+		}
+		page.Offset = n
+	}
+
+	page.Cursor = c.Query("cursor")
+	page.Sort = c.Query("sort")
+
+	return page, nil
+}
+
+// setLinkHeader advertises the neighbouring pages as RFC 8288 links. Offset
+// requests get offset links, everything else follows the cursors.
+func setLinkHeader(c *gin.Context, page *services.TodoPage) {
+	var links []string
+
+	addLink := func(rel string, params map[string]string) {
+		query := c.Request.URL.Query()
+		query.Del("cursor")
+		query.Del("offset")
+		query.Set("limit", strconv.Itoa(page.Limit))
+		for key, value := range params {
+			query.Set(key, value)
+		}
+
+		u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel))
+	}
+
+	if c.Query("offset") != "" {
+		if next := page.Offset + page.Limit; int64(next) < page.Total {
+			addLink("next", map[string]string{"offset": strconv.Itoa(next)})
+		}
+		if page.Offset > 0 {
+			addLink("prev", map[string]string{"offset": strconv.Itoa(max(page.Offset-page.Limit, 0))})
+		}
+	} else {
+		if page.NextCursor != "" {
+			addLink("next", map[string]string{"cursor": page.NextCursor})
+		}
+		if page.PrevCursor != "" {
+			addLink("prev", map[string]string{"cursor": page.PrevCursor})
+		}
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// todoPage is the listing response shared by every paged todo endpoint.
+type todoPage struct {
+	Todos      []models.ToDo `json:"todos"`
+	Total      int64         `json:"total"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+	NextCursor *string       `json:"next_cursor"`
+	PrevCursor *string       `json:"prev_cursor"`
+}
+
+func pageResponse(page *services.TodoPage) todoPage {
+	return todoPage{
+		Todos:      page.Todos,
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: nullable(page.NextCursor),
+		PrevCursor: nullable(page.PrevCursor),
+	}
+}
+
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// respondListError maps listing errors to 400 for bad paging, sort or filter
+// input and 500 for everything else.
+func respondListError(c *gin.Context, err error) {
+	var syntaxErr *filters.SyntaxError
+	if errors.Is(err, services.ErrInvalidPage) || errors.Is(err, services.ErrInvalidCursor) ||
+		errors.Is(err, services.ErrInvalidSort) || errors.Is(err, services.ErrInvalidDue) ||
+		errors.Is(err, services.ErrInvalidDate) || errors.Is(err, services.ErrInvalidTimezone) ||
+		errors.Is(err, services.ErrInvalidPriority) || errors.Is(err, services.ErrInvalidTags) ||
+		errors.Is(err, services.ErrInvalidState) ||
+		errors.As(err, &syntaxErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
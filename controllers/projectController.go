@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type projectBody struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func newProjectService(c *gin.Context) *services.ProjectService {
+	return services.NewProjectService(ownProjects(c))
+}
+
+// respondProjectForbidden is the answer to changing a project that is shared
+// with the caller, but not enough for the change.
+func respondProjectForbidden(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "Project is shared with you without this permission"})
+}
+
+// respondProjectNotFound is the answer to putting a todo into a project that
+// doesn't exist or that the caller can't file todos into.
+func respondProjectNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{"error": "Project doesn't exist"})
+}
+
+// ProjectIndex godoc
+// @Summary List projects
+// @Description Получение ваших проектов и расшаренных с вами, отсортированных по имени
+// @Tags projects
+// @Produce  json
+// @Success 200 {array} models.Project "Projects"
+// @Failure 500 {string} string "Internal server error"
+// @Router /projects [get]
+func ProjectIndex(c *gin.Context) {
+	projects, err := newProjectService(c).ListProjects()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"projects": projects,
+	})
+}
+
+// ProjectCreate godoc
+// @Summary Create a project
+// @Description Создание проекта, имя должно быть от 1 до 100 символов
+// @Tags projects
+// @Accept  json
+// @Produce  json
+// @Param project body projectBody true "Create Project"
+// @Success 201 {object} models.Project "Successfully created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Router /projects [post]
+func ProjectCreate(c *gin.Context) {
+	var body projectBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, name is required"})
+		return
+	}
+
+	project, err := newProjectService(c).CreateProject(body.Name, body.Description)
+	if err != nil {
+		respondProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"project": project,
+	})
+}
+
+// ProjectShow godoc
+// @Summary Show a project
+// @Description Получение проекта по id
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project "Project details"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Router /projects/{id} [get]
+func ProjectShow(c *gin.Context) {
+	project, err := newProjectService(c).FindProject(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project": project,
+	})
+}
+
+// ProjectUpdate godoc
+// @Summary Replace a project
+// @Description Замена имени и описания проекта, доступна владельцу и пользователям с ролью editor или owner
+// @Tags projects
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param project body projectBody true "Replace Project"
+// @Success 200 {object} models.Project "Successfully updated"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Shared without the editor role"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Router /projects/{id} [put]
+func ProjectUpdate(c *gin.Context) {
+	projectService := newProjectService(c)
+
+	project, err := projectService.FindProject(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body projectBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, name is required"})
+		return
+	}
+
+	if err := projectService.UpdateProject(project, body.Name, body.Description); err != nil {
+		respondProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project": project,
+	})
+}
+
+// ProjectDelete godoc
+// @Summary Delete a project
+// @Description Удаление проекта, доступно только его владельцу: по умолчанию его todo переносятся во входящие,
+// @Description с cascade=true — в корзину. Todo из корзины и todo других пользователей в любом случае теряют проект
+// @Tags projects
+// @Param id path int true "Project ID"
+// @Param cascade query bool false "Move the todos of the project to the trash"
+// @Success 204 {string} string "Successfully deleted"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Not the owner of the project"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Router /projects/{id} [delete]
+func ProjectDelete(c *gin.Context) {
+	projectService := newProjectService(c)
+
+	cascade := false
+	if value := c.Query("cascade"); value != "" {
+		var err error
+		if cascade, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong cascade format"})
+			return
+		}
+	}
+
+	project, err := projectService.FindProject(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := projectService.DeleteProject(project, cascade); err != nil {
+		respondProjectError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ProjectTodos godoc
+// @Summary List todos of a project
+// @Description Получение списка todo проекта с теми же фильтрами, сортировкой и страницами, что и GET /todo
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param status query bool false "Filter by status"
+// @Param state query string false "Comma separated states, any of which matches, e.g. in_progress,in_review"
+// @Param priority query string false "Comma separated priorities, any of which matches, e.g. high,urgent"
+// @Param tags query string false "Comma separated tag names, any of which matches"
+// @Param tags_all query string false "Comma separated tag names, all of which must match"
+// @Param filter query string false "Filter expression, see GET /todo"
+// @Param due query string false "Due window: overdue (past due and neither done nor cancelled), today or week" Enums(overdue, today, week)
+// @Param due_after query string false "Due at or after this date or RFC 3339 time"
+// @Param due_before query string false "Due before this date or RFC 3339 time"
+// @Param tz query string false "IANA time zone for today, week and dates without offset (default UTC)"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of todos to skip"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor"
+// @Param sort query string false "Sort fields, see GET /todo"
+// @Success 200 {object} todoPage "Page of todos"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Router /projects/{id}/todos [get]
+func ProjectTodos(c *gin.Context) {
+	project, err := newProjectService(c).FindProject(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	listTodos(c, services.TodoFilter{ProjectID: &project.ID})
+}
+
+func respondProjectError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, services.ErrForbidden):
+		respondProjectForbidden(c)
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project doesn't exist"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"example/Studying/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Routes registers the API on r. Every route but the auth ones needs a
+// login, and all but API key management a role with the permission the
+// route's group requires, checked before the handler runs.
+func Routes(r gin.IRouter) {
+	r.POST("/auth/register", AuthRegister)
+	r.POST("/auth/login", AuthLogin)
+	r.POST("/auth/refresh", AuthRefresh)
+	r.POST("/auth/logout", AuthLogout)
+
+	api := r.Group("/", Authenticate)
+
+	api.GET("/api-keys", APIKeyIndex)
+	api.POST("/api-keys", APIKeyCreate)
+	api.DELETE("/api-keys/:id", APIKeyDelete)
+
+	read := api.Group("/", Require(models.PermissionRead))
+
+	read.GET("/todo", ToDoIndex)
+	read.GET("/todo/search", ToDoSearch)
+	read.GET("/todo/trash", ToDoTrash)
+	read.GET("/todo/:id", ToDoShow)
+	read.GET("/series/:id", SeriesShow)
+	read.GET("/todo/:id/subtasks", SubtaskIndex)
+	read.GET("/todo/:id/subtasks/:subtaskId", SubtaskShow)
+	read.GET("/todo/:id/comments", CommentIndex)
+	read.GET("/todo/:id/comments/:commentId", CommentShow)
+	read.GET("/todo/:id/attachments", AttachmentIndex)
+	read.GET("/todo/:id/attachments/:attachmentId", AttachmentShow)
+	read.GET("/todo/:id/attachments/:attachmentId/content", AttachmentContent)
+	read.GET("/todo/:id/shares", ToDoShareIndex)
+	read.GET("/tags", TagIndex)
+	read.GET("/tags/:id", TagShow)
+	read.GET("/projects", ProjectIndex)
+	read.GET("/projects/:id", ProjectShow)
+	read.GET("/projects/:id/todos", ProjectTodos)
+	read.GET("/projects/:id/shares", ProjectShareIndex)
+
+	write := api.Group("/", Require(models.PermissionWrite))
+
+	write.POST("/todo", ToDoCreate)
+	write.PUT("/todo/:id", ToDoUpdate)
+	write.PATCH("/todo/:id", ToDoPatch)
+	write.DELETE("/todo/:id", ToDoDelete)
+	write.POST("/todo/:id/restore", ToDoRestore)
+	write.POST("/todo/:id/move", ToDoMove)
+	write.PUT("/todo/:id/tags/:tagId", ToDoAttachTag)
+	write.DELETE("/todo/:id/tags/:tagId", ToDoDetachTag)
+	write.POST("/todo/:id/subtasks", SubtaskCreate)
+	write.PUT("/todo/:id/subtasks/:subtaskId", SubtaskUpdate)
+	write.PATCH("/todo/:id/subtasks/:subtaskId", SubtaskPatch)
+	write.DELETE("/todo/:id/subtasks/:subtaskId", SubtaskDelete)
+	write.POST("/todo/:id/comments", CommentCreate)
+	write.PUT("/todo/:id/comments/:commentId", CommentUpdate)
+	write.DELETE("/todo/:id/comments/:commentId", CommentDelete)
+	write.POST("/todo/:id/attachments", AttachmentCreate)
+	write.DELETE("/todo/:id/attachments/:attachmentId", AttachmentDelete)
+	write.POST("/todo/:id/shares", ToDoShareCreate)
+	write.DELETE("/todo/:id/shares/:shareId", ToDoShareDelete)
+	write.POST("/tags", TagCreate)
+	write.PUT("/tags/:id", TagUpdate)
+	write.DELETE("/tags/:id", TagDelete)
+	write.POST("/projects", ProjectCreate)
+	write.PUT("/projects/:id", ProjectUpdate)
+	write.DELETE("/projects/:id", ProjectDelete)
+	write.POST("/projects/:id/shares", ProjectShareCreate)
+	write.DELETE("/projects/:id/shares/:shareId", ProjectShareDelete)
+
+	admin := api.Group("/admin", Require(models.PermissionManageUsers))
+
+	admin.GET("/users", UserIndex)
+	admin.PUT("/users/:id/role", UserSetRole)
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesShow godoc
+// @Summary Show a series of recurring todos
+// @Description Получение серии повторяющихся todo по SeriesID: правило, начало и поля, с которыми создаются следующие вхождения
+// @Tags todos
+// @Produce  json
+// @Param id path int true "Series ID"
+// @Success 200 {object} models.Series "Series details"
+// @Failure 404 {object} map[string]string "Series not found"
+// @Router /series/{id} [get]
+func SeriesShow(c *gin.Context) {
+	series, err := newTodoService(c).FindSeries(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"series": series,
+	})
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/initializers"
+	"example/Studying/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shareBody is the POST payload; user is a username or an email.
+type shareBody struct {
+	User string `json:"user" binding:"required"`
+	Role string `json:"role" binding:"required" enums:"viewer,editor,owner"`
+}
+
+func newShareService(c *gin.Context) *services.ShareService {
+	return services.NewShareService(initializers.ShareRepository, initializers.UserRepository,
+		ownProjects(c), ownTodos(c), currentUser(c).ID)
+}
+
+// respondForbidden is the answer to changing a todo that is shared with the
+// caller, but not enough for the change.
+func respondForbidden(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "ToDo is shared with you without this permission"})
+}
+
+// ToDoShareIndex godoc
+// @Summary List shares of a todo
+// @Description Получение пользователей, с которыми todo расшарен напрямую (без учета проектов)
+// @Tags shares
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Success 200 {array} models.Share "Shares"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /todo/{id}/shares [get]
+func ToDoShareIndex(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	shares, err := newShareService(c).ListTodoShares(todo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shares"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": shares,
+	})
+}
+
+// ToDoShareCreate godoc
+// @Summary Share a todo
+// @Description Открытие доступа к todo пользователю по username или email: viewer — просмотр,
+// @Description editor — еще и изменение, owner — еще и удаление, восстановление и открытие доступа другим
+// @Description Делиться todo могут его владелец и пользователи с ролью owner
+// @Tags shares
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param share body shareBody true "Share Todo"
+// @Success 201 {object} models.Share "Successfully shared"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Shared without the owner role"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 409 {object} map[string]string "Already shared with the user"
+// @Router /todo/{id}/shares [post]
+func ToDoShareCreate(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	var body shareBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, user and role are required"})
+		return
+	}
+
+	share, err := newShareService(c).ShareTodo(todo, body.User, body.Role)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"share": share,
+	})
+}
+
+// ToDoShareDelete godoc
+// @Summary Revoke a share of a todo
+// @Description Закрытие доступа к todo: владельцы todo могут закрыть любой доступ, остальные — только свой
+// @Tags shares
+// @Param id path int true "Todo ID"
+// @Param shareId path int true "Share ID"
+// @Success 204 {string} string "Successfully revoked"
+// @Failure 403 {object} map[string]string "Shared without the owner role"
+// @Failure 404 {object} map[string]string "Todo or share not found"
+// @Router /todo/{id}/shares/{shareId} [delete]
+func ToDoShareDelete(c *gin.Context) {
+	todo, err := newTodoService(c).FindTodo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ToDo doesn't exist"})
+		return
+	}
+
+	if err := newShareService(c).RevokeTodoShare(todo, c.Param("shareId")); err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ProjectShareIndex godoc
+// @Summary List shares of a project
+// @Description Получение пользователей, с которыми расшарен проект
+// @Tags shares
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Success 200 {array} models.Share "Shares"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Router /projects/{id}/shares [get]
+func ProjectShareIndex(c *gin.Context) {
+	shares, err := newShareService(c).ListProjectShares(c.Param("id"))
+	if err != nil {
+		respondProjectShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": shares,
+	})
+}
+
+// ProjectShareCreate godoc
+// @Summary Share a project
+// @Description Открытие доступа к проекту и всем todo в нем, в том числе добавленным позже другими участниками, пользователю по username или email
+// @Description Роли те же, что и у доступа к отдельному todo, editor также может переименовать проект
+// @Description Делиться проектом могут его владелец и пользователи с ролью owner
+// @Tags shares
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param share body shareBody true "Share Project"
+// @Success 201 {object} models.Share "Successfully shared"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Shared without the owner role"
+// @Failure 404 {object} map[string]string "Project not found"
+// @Failure 409 {object} map[string]string "Already shared with the user"
+// @Router /projects/{id}/shares [post]
+func ProjectShareCreate(c *gin.Context) {
+	var body shareBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, user and role are required"})
+		return
+	}
+
+	share, err := newShareService(c).ShareProject(c.Param("id"), body.User, body.Role)
+	if err != nil {
+		respondProjectShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"share": share,
+	})
+}
+
+// ProjectShareDelete godoc
+// @Summary Revoke a share of a project
+// @Description Закрытие доступа к проекту: владельцы проекта могут закрыть любой доступ, остальные — только свой
+// @Tags shares
+// @Param id path int true "Project ID"
+// @Param shareId path int true "Share ID"
+// @Success 204 {string} string "Successfully revoked"
+// @Failure 403 {object} map[string]string "Shared without the owner role"
+// @Failure 404 {object} map[string]string "Project or share not found"
+// @Router /projects/{id}/shares/{shareId} [delete]
+func ProjectShareDelete(c *gin.Context) {
+	if err := newShareService(c).RevokeProjectShare(c.Param("id"), c.Param("shareId")); err != nil {
+		respondProjectShareError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondShareError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, services.ErrForbidden):
+		respondForbidden(c)
+	case errors.Is(err, services.ErrDuplicateShare):
+		c.JSON(http.StatusConflict, gin.H{"error": "Already shared with this user"})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share doesn't exist"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func respondProjectShareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project or share doesn't exist"})
+	case errors.Is(err, services.ErrForbidden):
+		respondProjectForbidden(c)
+	default:
+		respondShareError(c, err)
+	}
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// subtaskBody is the POST and PATCH payload; position is 1-based and a new
+// subtask without one is appended.
+type subtaskBody struct {
+	Title    *string `json:"title"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position"`
+}
+
+// replaceSubtaskBody is the PUT payload; leaving out position keeps the
+// subtask where it is.
+type replaceSubtaskBody struct {
+	Title    *string `json:"title" binding:"required"`
+	Done     *bool   `json:"done" binding:"required"`
+	Position *int    `json:"position"`
+}
+
+type subtaskList struct {
+	Subtasks   []models.Subtask `json:"subtasks"`
+	Completion *int             `json:"completion"`
+}
+
+func newSubtaskService(c *gin.Context) *services.SubtaskService {
+	return services.NewSubtaskService(initializers.SubtaskRepository, newTodoService(c))
+}
+
+// SubtaskIndex godoc
+// @Summary List subtasks
+// @Description Получение подзадач todo по порядку и процента выполнения (null, если подзадач нет)
+// @Tags subtasks
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Success 200 {object} subtaskList "Subtasks"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /todo/{id}/subtasks [get]
+func SubtaskIndex(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	subtasks, err := newSubtaskService(c).ListSubtasks(todo)
+	if err != nil {
+		respondSubtaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subtaskList{Subtasks: subtasks, Completion: services.Completion(subtasks)})
+}
+
+// SubtaskCreate godoc
+// @Summary Create a subtask
+// @Description Добавление подзадачи в todo, без position подзадача добавляется в конец.
+// @Description Когда все подзадачи выполнены, todo тоже становится выполненным
+// @Tags subtasks
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param subtask body subtaskBody true "Create Subtask"
+// @Success 201 {object} models.Subtask "Successfully created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Router /todo/{id}/subtasks [post]
+func SubtaskCreate(c *gin.Context) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return
+	}
+
+	var body subtaskBody
+	if err := c.ShouldBindJSON(&body); err != nil || body.Title == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, title is required"})
+		return
+	}
+
+	subtask, err := newSubtaskService(c).CreateSubtask(todo, services.SubtaskFields(body))
+	if err != nil {
+		respondSubtaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"subtask": subtask,
+	})
+}
+
+// SubtaskShow godoc
+// @Summary Show a subtask
+// @Description Получение подзадачи todo по id
+// @Tags subtasks
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param subtaskId path int true "Subtask ID"
+// @Success 200 {object} models.Subtask "Subtask details"
+// @Failure 404 {object} map[string]string "Todo or subtask not found"
+// @Router /todo/{id}/subtasks/{subtaskId} [get]
+func SubtaskShow(c *gin.Context) {
+	_, subtask, ok := findSubtask(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subtask": subtask,
+	})
+}
+
+// SubtaskUpdate godoc
+// @Summary Replace a subtask
+// @Description Полная замена подзадачи: title и done обязательны, без position подзадача остается на месте
+// @Tags subtasks
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param subtaskId path int true "Subtask ID"
+// @Param subtask body replaceSubtaskBody true "Replace Subtask"
+// @Success 200 {object} models.Subtask "Successfully updated"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo or subtask not found"
+// @Router /todo/{id}/subtasks/{subtaskId} [put]
+func SubtaskUpdate(c *gin.Context) {
+	_, subtask, ok := findSubtask(c)
+	if !ok {
+		return
+	}
+
+	var body replaceSubtaskBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, title and done are required"})
+		return
+	}
+
+	updateSubtask(c, subtask, services.SubtaskFields(body))
+}
+
+// SubtaskPatch godoc
+// @Summary Patch a subtask
+// @Description Частичное обновление подзадачи, меняются только переданные поля
+// @Tags subtasks
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param subtaskId path int true "Subtask ID"
+// @Param subtask body subtaskBody true "Patch Subtask"
+// @Success 200 {object} models.Subtask "Successfully updated"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo or subtask not found"
+// @Router /todo/{id}/subtasks/{subtaskId} [patch]
+func SubtaskPatch(c *gin.Context) {
+	_, subtask, ok := findSubtask(c)
+	if !ok {
+		return
+	}
+
+	var body subtaskBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format"})
+		return
+	}
+
+	updateSubtask(c, subtask, services.SubtaskFields(body))
+}
+
+// SubtaskDelete godoc
+// @Summary Delete a subtask
+// @Description Удаление подзадачи todo
+// @Tags subtasks
+// @Param id path int true "Todo ID"
+// @Param subtaskId path int true "Subtask ID"
+// @Success 204 {string} string "Successfully deleted"
+// @Failure 404 {object} map[string]string "Todo or subtask not found"
+// @Router /todo/{id}/subtasks/{subtaskId} [delete]
+func SubtaskDelete(c *gin.Context) {
+	_, subtask, ok := findSubtask(c)
+	if !ok {
+		return
+	}
+
+	if err := newSubtaskService(c).DeleteSubtask(subtask); err != nil {
+		respondSubtaskError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func updateSubtask(c *gin.Context, subtask *models.Subtask, fields services.SubtaskFields) {
+	if err := newSubtaskService(c).UpdateSubtask(subtask, fields); err != nil {
+		respondSubtaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subtask": subtask,
+	})
+}
+
+// findTodo loads the todo named by the :id parameter, responding with 404
+// if there is none. Requests that change something also need the todo to be
+// the caller's or shared with them as an editor, otherwise they get a 403.
+func findTodo(c *gin.Context) (*models.ToDo, bool) {
+	todoService := newTodoService(c)
+	todo, err := todoService.FindTodo(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ToDo doesn't exist"})
+		return nil, false
+	}
+
+	if models.ScopeFor(c.Request.Method) == models.ScopeWrite {
+		if err := todoService.RequireRole(todo, models.ShareEditor); err != nil {
+			if errors.Is(err, services.ErrForbidden) {
+				respondForbidden(c)
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+	}
+
+	return todo, true
+}
+
+func findSubtask(c *gin.Context) (*models.ToDo, *models.Subtask, bool) {
+	todo, ok := findTodo(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	subtask, err := newSubtaskService(c).FindSubtask(todo, c.Param("subtaskId"))
+	if err != nil {
+		respondSubtaskError(c, err)
+		return nil, nil, false
+	}
+
+	return todo, subtask, true
+}
+
+func respondSubtaskError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtask doesn't exist"})
+	case errors.Is(err, services.ErrVersionConflict):
+		respondVersionConflict(c)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
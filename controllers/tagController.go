@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/initializers"
+	"example/Studying/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type tagBody struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func newTagService(c *gin.Context) *services.TagService {
+	return services.NewTagService(initializers.TagRepository, ownTodos(c), currentUser(c).ID)
+}
+
+// TagIndex godoc
+// @Summary List tags
+// @Description Получение ваших тегов, отсортированных по имени
+// @Tags tags
+// @Produce  json
+// @Success 200 {array} models.Tag "Tags"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tags [get]
+func TagIndex(c *gin.Context) {
+	tags, err := newTagService(c).ListTags()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+// TagCreate godoc
+// @Summary Create a tag
+// @Description Создание тега, имя должно быть уникальным среди ваших тегов, от 1 до 64 символов и без запятых
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param tag body tagBody true "Create Tag"
+// @Success 201 {object} models.Tag "Successfully created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Tag already exists"
+// @Router /tags [post]
+func TagCreate(c *gin.Context) {
+	var body tagBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, name is required"})
+		return
+	}
+
+	tag, err := newTagService(c).CreateTag(body.Name)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"tag": tag,
+	})
+}
+
+// TagShow godoc
+// @Summary Show a tag
+// @Description Получение тега по id
+// @Tags tags
+// @Produce  json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} models.Tag "Tag details"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Router /tags/{id} [get]
+func TagShow(c *gin.Context) {
+	tag, err := newTagService(c).FindTag(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tag": tag,
+	})
+}
+
+// TagUpdate godoc
+// @Summary Rename a tag
+// @Description Переименование тега, новое имя видно у всех todo с этим тегом
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tag ID"
+// @Param tag body tagBody true "Rename Tag"
+// @Success 200 {object} models.Tag "Successfully renamed"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Failure 409 {object} map[string]string "Tag already exists"
+// @Router /tags/{id} [put]
+func TagUpdate(c *gin.Context) {
+	tagService := newTagService(c)
+
+	tag, err := tagService.FindTag(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body tagBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, name is required"})
+		return
+	}
+
+	if err := tagService.RenameTag(tag, body.Name); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tag": tag,
+	})
+}
+
+// TagDelete godoc
+// @Summary Delete a tag
+// @Description Удаление тега, он снимается со всех todo
+// @Tags tags
+// @Param id path int true "Tag ID"
+// @Success 204 {string} string "Successfully deleted"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Router /tags/{id} [delete]
+func TagDelete(c *gin.Context) {
+	tagService := newTagService(c)
+
+	tag, err := tagService.FindTag(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tagService.DeleteTag(tag); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ToDoAttachTag godoc
+// @Summary Attach a tag to a todo
+// @Description Добавление вашего тега к todo, повторное добавление ничего не меняет
+// @Tags tags
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param tagId path int true "Tag ID"
+// @Success 200 {object} models.ToDo "Todo with its tags"
+// @Failure 403 {object} map[string]string "Shared for viewing only"
+// @Failure 404 {object} map[string]string "Todo or tag not found"
+// @Router /todo/{id}/tags/{tagId} [put]
+func ToDoAttachTag(c *gin.Context) {
+	todo, err := newTagService(c).AttachTag(c.Param("id"), c.Param("tagId"))
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	setETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"todo": todo,
+	})
+}
+
+// ToDoDetachTag godoc
+// @Summary Detach a tag from a todo
+// @Description Удаление тега у todo, в том числе добавленного другим пользователем
+// @Tags tags
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param tagId path int true "Tag ID"
+// @Success 200 {object} models.ToDo "Todo with its tags"
+// @Failure 403 {object} map[string]string "Shared for viewing only"
+// @Failure 404 {object} map[string]string "Todo not found or does not have the tag"
+// @Router /todo/{id}/tags/{tagId} [delete]
+func ToDoDetachTag(c *gin.Context) {
+	todo, err := newTagService(c).DetachTag(c.Param("id"), c.Param("tagId"))
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	setETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"todo": todo,
+	})
+}
+
+func respondTagError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, services.ErrDuplicateTag):
+		c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists"})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "ToDo or tag doesn't exist"})
+	case errors.Is(err, services.ErrForbidden):
+		respondForbidden(c)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,30 +1,74 @@
 package controllers
 
 import (
+	"errors"
+	"example/Studying/initializers"
 	"example/Studying/models"
 	"example/Studying/services"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+func newTodoService(c *gin.Context) *services.TodoService {
+	return services.NewTodoService(initializers.TodoRepository).
+		WithSeries(initializers.SeriesRepository).
+		WithWorkflow(initializers.Workflow).
+		WithAttachments(newAttachmentService()).
+		ForUser(currentUser(c).ID, initializers.ShareRepository, initializers.ProjectRepository)
+}
+
+// body is the POST payload. start_at and due_at are optional RFC 3339 times
+// with an offset, e.g. "2024-01-31T18:00:00+03:00". Without project_id the
+// todo goes to the inbox. rrule makes the todo recur, see package recurrence.
+// state defaults to the initial state of the workflow, or done with status.
 type body struct {
-	Title  string `json:"title"`
-	Body   string `json:"body"`
-	Status bool   `json:"status"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Status    bool       `json:"status"`
+	State     string     `json:"state" enums:"backlog,in_progress,blocked,in_review,done,cancelled"`
+	Priority  string     `json:"priority" enums:"none,low,medium,high,urgent"`
+	StartAt   *time.Time `json:"start_at"`
+	DueAt     *time.Time `json:"due_at"`
+	ProjectID *uint      `json:"project_id"`
+	RRule     string     `json:"rrule" example:"FREQ=WEEKLY;BYDAY=MO"`
+}
+
+// replaceBody is the PUT payload: a full representation, so every field is
+// required and nothing is silently reset to its zero value. The dates,
+// project and rrule are nullable and priority defaults to none, so leaving
+// them out clears them. Leaving out state keeps it, unless status changes.
+type replaceBody struct {
+	Title     *string    `json:"title" binding:"required"`
+	Body      *string    `json:"body" binding:"required"`
+	Status    *bool      `json:"status" binding:"required"`
+	State     string     `json:"state" enums:"backlog,in_progress,blocked,in_review,done,cancelled"`
+	Priority  string     `json:"priority" enums:"none,low,medium,high,urgent"`
+	StartAt   *time.Time `json:"start_at"`
+	DueAt     *time.Time `json:"due_at"`
+	ProjectID *uint      `json:"project_id"`
+	RRule     string     `json:"rrule" example:"FREQ=WEEKLY;BYDAY=MO"`
 }
 
 // ToDoCreate godoc
 // @Summary Create a new todo
 // @Description Создание нового todo
 // @Description title должен быть не короче 3
+// @Description start_at и due_at необязательны, start_at не может быть позже due_at
+// @Description priority: none (по умолчанию), low, medium, high или urgent
+// @Description project_id необязателен, без него todo попадает во входящие; проект должен принадлежать пользователю или быть расшарен ему с ролью editor или owner
+// @Description rrule — правило повторения iCalendar (FREQ=DAILY|WEEKLY|MONTHLY|YEARLY, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH),
+// @Description повторяющемуся todo нужен due_at
+// @Description state: backlog (по умолчанию), in_progress, blocked, in_review, done или cancelled; status=true создает todo в состоянии done
 // @Tags todos
 // @Accept  json
 // @Produce  json
 // @Param todo body body true "Create Todo"
 // @Success 201 {object} models.ToDo "Successfully created"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Project not found or not editable"
 // @Router /todo [post]
 func ToDoCreate(c *gin.Context) {
 	//Get data
@@ -32,21 +76,36 @@ func ToDoCreate(c *gin.Context) {
 
 	c.Bind(&body)
 
-	if len(body.Title) < 3 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Title have to be more than 3 letters"})
-		return
-	}
-
 	// Create a ToDo using the service
-	todoService := services.NewTodoService()
-	todo, err := todoService.CreateTodo(body.Title, body.Body, body.Status)
+	todoService := newTodoService(c)
+	todo, err := todoService.CreateTodoFields(services.TodoFields{
+		Title:     body.Title,
+		Body:      body.Body,
+		Status:    body.Status,
+		State:     body.State,
+		Priority:  body.Priority,
+		StartAt:   body.StartAt,
+		DueAt:     body.DueAt,
+		ProjectID: body.ProjectID,
+		RRule:     body.RRule,
+	})
 
 	if err != nil {
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+			return
+		}
+		if errors.Is(err, services.ErrProjectNotFound) {
+			respondProjectNotFound(c)
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong todo format"})
 		return
 	}
 
 	// Return data
+	setETag(c, todo)
 	c.JSON(http.StatusCreated, gin.H{
 		"todo": todo,
 	})
@@ -55,18 +114,50 @@ func ToDoCreate(c *gin.Context) {
 // ToDoIndex godoc
 // @Summary List todos
 // @Description Получение списка todo, опционально можно отфильтровать по статусу
+// @Description или выражением filter: поля id, title, body, status, state, created_at, updated_at, start_at, due_at, completed_at,
+// @Description операторы = != < <= > >= contains, связки and/or/not и скобки
+// @Description По умолчанию todo упорядочены по приоритету, затем по сроку выполнения (без срока — в конце)
+// @Description Список отдается страницами: limit/offset или cursor из next_cursor/prev_cursor,
+// @Description ссылки на соседние страницы также передаются в заголовке Link
+// @Description Параметры due, due_after и due_before отбирают todo по сроку выполнения
+// @Description CommentCount у каждого todo — число его комментариев
 // @Tags todos
 // @Accept  json
 // @Produce  json
 // @Param status query bool false "Filter by status"
-// @Success 200 {array} models.ToDo "List of todos"
+// @Param state query string false "Comma separated states, any of which matches, e.g. in_progress,in_review"
+// @Param priority query string false "Comma separated priorities, any of which matches, e.g. high,urgent"
+// @Param tags query string false "Comma separated tag names, any of which matches"
+// @Param tags_all query string false "Comma separated tag names, all of which must match"
+// @Param filter query string false "Filter expression, e.g. title contains \"report\" and (status = false or created_at >= \"2024-01-01\")"
+// @Param due query string false "Due window: overdue (past due and neither done nor cancelled), today or week" Enums(overdue, today, week)
+// @Param due_after query string false "Due at or after this date or RFC 3339 time"
+// @Param due_before query string false "Due before this date or RFC 3339 time"
+// @Param tz query string false "IANA time zone for today, week and dates without offset (default UTC)"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of todos to skip"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor"
+// @Param sort query string false "Sort fields: id, title, status, priority, rank, created_at, updated_at, start_at, due_at, completed_at (todos without the date come last); prefix with - or suffix with :desc for descending, e.g. -created_at,title. Default (smart): highest priority first, then earliest due date"
+// @Success 200 {object} todoPage "Page of todos"
+// @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 500 {string} string "Internal server error"
 // @Router /todo [get]
 func ToDoIndex(c *gin.Context) {
+	listTodos(c, services.TodoFilter{})
+}
+
+// listTodos responds with the page of todos the query string asks for,
+// narrowed further by filter.
+func listTodos(c *gin.Context, filter services.TodoFilter) {
 	//Get data
-	var todos []models.ToDo
 	status := c.Query("status")
-	todoService := services.NewTodoService()
+	todoService := newTodoService(c)
+
+	pageRequest, err := bindPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	//check param
 	if status != "" {
@@ -75,42 +166,106 @@ func ToDoIndex(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong todo format"})
 			return
 		}
+		filter.Status = &filteredStatus
+	}
+	filter.Expr = c.Query("filter")
+	filter.States = c.Query("state")
+	filter.Priorities = c.Query("priority")
+	filter.AnyTags = c.Query("tags")
+	filter.AllTags = c.Query("tags_all")
+	filter.Due = services.DueFilter{
+		Window: c.Query("due"),
+		Before: c.Query("due_before"),
+		After:  c.Query("due_after"),
+		TZ:     c.Query("tz"),
+	}
 
-		todos, err = todoService.FindByStatus(filteredStatus)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-	} else {
-		var err error
-		todos, err = todoService.GetAllTodos()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, err := todoService.ListTodos(filter, pageRequest)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	//Respond with data
+	setLinkHeader(c, page)
+	c.JSON(http.StatusOK, pageResponse(page))
+}
+
+type searchResult struct {
+	ToDo    models.ToDo `json:"todo"`
+	Rank    float64     `json:"rank"`
+	Snippet string      `json:"snippet"`
+}
+
+type searchResponse struct {
+	Results []searchResult `json:"results"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+// ToDoSearch godoc
+// @Summary Search todos
+// @Description Полнотекстовый поиск по title и body, результаты отсортированы по релевантности
+// @Description snippet содержит экранированный фрагмент текста, совпадения обернуты в <mark>
+// @Tags todos
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of results to skip"
+// @Success 200 {object} searchResponse "Ranked results"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todo/search [get]
+func ToDoSearch(c *gin.Context) {
+	todoService := newTodoService(c)
+
+	pageRequest, err := bindPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := todoService.SearchTodos(c.Query("q"), pageRequest)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		respondListError(c, err)
+		return
 	}
 
-	//Respond with data
-	c.JSON(http.StatusOK, gin.H{
-		"todos": todos,
-	})
+	response := searchResponse{
+		Results: make([]searchResult, len(page.Hits)),
+		Limit:   page.Limit,
+		Offset:  page.Offset,
+	}
+	for i, hit := range page.Hits {
+		response.Results[i] = searchResult{ToDo: hit.Todo, Rank: hit.Rank, Snippet: hit.Snippet}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // ToDoShow godoc
 // @Summary Show a todo
-// @Description Получение todo по id
+// @Description Получение todo по id, в заголовке ETag передается версия todo
+// @Description completion — процент выполненных подзадач (null, если подзадач нет)
 // @Tags todos
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Todo ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} models.ToDo "Todo details"
+// @Success 304 {string} string "Not modified"
 // @Failure 404 {object} map[string]string "Todo not found"
 // @Router /todo/{id} [get]
 func ToDoShow(c *gin.Context) {
 	//Get param
 	id := c.Param("id")
 
-	todoService := services.NewTodoService()
+	todoService := newTodoService(c)
 
 	todo, err := todoService.FindTodo(id)
 	if err != nil {
@@ -118,29 +273,53 @@ func ToDoShow(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, todo) {
+		return
+	}
+
+	completion, err := newSubtaskService(c).Completion(todo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	//Respond with single todo
+	setETag(c, todo)
 	c.JSON(http.StatusOK, gin.H{
-		"todo": todo,
+		"todo":       todo,
+		"completion": completion,
 	})
 }
 
 // ToDoUpdate godoc
-// @Summary Update a todo
-// @Description Обновление todo по id
+// @Summary Replace a todo
+// @Description Полная замена todo по id: title, body и status обязательны,
+// @Description title должен быть не короче 3. Для частичного обновления используйте PATCH
+// @Description Не переданные start_at, due_at, priority, project_id и rrule сбрасываются, не переданный state сохраняется
+// @Description Переходы state проверяются по workflow, недопустимый переход — 409. status=true переводит todo в done,
+// @Description status=false у выполненного todo — в начальное состояние
+// @Description У повторяющегося todo scope=this меняет только это вхождение, scope=future — и все следующие;
+// @Description правило повторения меняется только с scope=future. Если изменение завершает последнее вхождение,
+// @Description в ответе next — созданное следующее вхождение
 // @Tags todos
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Todo ID"
-// @Param todo body body false "Update Todo"
+// @Param scope query string false "Occurrences of a recurring todo to change" Enums(this, future)
+// @Param If-Match header string false "ETag the change is based on"
+// @Param todo body replaceBody true "Replace Todo"
 // @Success 200 {object} models.ToDo "Successfully updated"
-// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo or project not found"
+// @Failure 409 {object} map[string]string "Transition not allowed by the workflow"
+// @Failure 412 {object} map[string]string "Todo was modified"
 // @Router /todo/{id} [put]
 func ToDoUpdate(c *gin.Context) {
 	//Get param
 	id := c.Param("id")
 
 	//Get todo
-	todoService := services.NewTodoService()
+	todoService := newTodoService(c)
 	todo, err := todoService.FindTodo(id)
 
 	if err != nil {
@@ -148,43 +327,281 @@ func ToDoUpdate(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, todo) {
+		return
+	}
+
+	scope, err := services.ParseEditScope(c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	//Get data
-	var body body
+	var body replaceBody
 
-	if err := c.Bind(&body); err != nil {
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, title, body and status are required"})
+		return
+	}
+
+	//Replace todo
+	fields := services.TodoFields{
+		Title:     *body.Title,
+		Body:      *body.Body,
+		Status:    *body.Status,
+		State:     body.State,
+		Priority:  body.Priority,
+		StartAt:   body.StartAt,
+		DueAt:     body.DueAt,
+		ProjectID: body.ProjectID,
+		RRule:     body.RRule,
+	}
+	next, err := todoService.ReplaceTodoScoped(todo, fields, scope)
+	if err != nil {
+		respondUpdateError(c, err)
+		return
+	}
+
+	//Respond with updated todo
+	respondUpdated(c, todo, next)
+}
+
+// ToDoPatch godoc
+// @Summary Patch a todo
+// @Description Частичное обновление todo по id, меняются только переданные поля.
+// @Description application/merge-patch+json (или application/json) — JSON Merge Patch (RFC 7396),
+// @Description application/json-patch+json — JSON Patch (RFC 6902)
+// @Description scope и next для повторяющихся todo — как у PUT
+// @Tags todos
+// @Accept  json
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param scope query string false "Occurrences of a recurring todo to change" Enums(this, future)
+// @Param If-Match header string false "ETag the change is based on"
+// @Param patch body object true "Merge patch object or JSON Patch operations"
+// @Success 200 {object} models.ToDo "Successfully updated"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo or project not found"
+// @Failure 412 {object} map[string]string "Todo was modified"
+// @Failure 409 {object} map[string]string "Patch test operation failed or transition not allowed by the workflow"
+// @Failure 415 {object} map[string]string "Unsupported patch content type"
+// @Router /todo/{id} [patch]
+func ToDoPatch(c *gin.Context) {
+	//Get param
+	id := c.Param("id")
+
+	//Get todo
+	todoService := newTodoService(c)
+	todo, err := todoService.FindTodo(id)
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ToDo doesn't exist"})
+		return
+	}
+
+	if !checkIfMatch(c, todo) {
+		return
+	}
+
+	scope, err := services.ParseEditScope(c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	//Get patch
+	patch, err := c.GetRawData()
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format"})
 		return
 	}
 
-	//Update todo
-	if err := todoService.UpdateTodo(todo, body.Title, body.Body, body.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	//Patch todo
+	next, err := todoService.PatchTodoScoped(todo, c.ContentType(), patch, scope)
+	if err != nil {
+		respondUpdateError(c, err)
 		return
 	}
 
 	//Respond with updated todo
+	respondUpdated(c, todo, next)
+}
+
+// respondUpdated responds with an updated todo and, if the update completed
+// a recurring todo, the next occurrence it created.
+func respondUpdated(c *gin.Context, todo *models.ToDo, next *models.ToDo) {
+	response := gin.H{
+		"todo": todo,
+	}
+	if next != nil {
+		response["next"] = next
+	}
+
+	setETag(c, todo)
+	c.JSON(http.StatusOK, response)
+}
+
+// moveBody names the todos a moved todo goes between. Either may be left out.
+type moveBody struct {
+	After  *uint `json:"after"`
+	Before *uint `json:"before"`
+}
+
+// ToDoMove godoc
+// @Summary Move a todo
+// @Description Перемещение todo в ручном порядке (sort=rank): after — id todo, после которого его поставить,
+// @Description before — id todo, перед которым его поставить. Можно передать оба, тогда todo встанет между ними.
+// @Description Меняется только rank перемещаемого todo
+// @Tags todos
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag the move is based on"
+// @Param move body moveBody true "Neighbours"
+// @Success 200 {object} models.ToDo "Successfully moved"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 412 {object} map[string]string "Todo was modified"
+// @Router /todo/{id}/move [post]
+func ToDoMove(c *gin.Context) {
+	//Get todo
+	todoService := newTodoService(c)
+	todo, err := todoService.FindTodo(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ToDo doesn't exist"})
+		return
+	}
+
+	if !checkIfMatch(c, todo) {
+		return
+	}
+
+	//Get data
+	var body moveBody
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, after and before have to be todo ids"})
+		return
+	}
+
+	//Move todo
+	if err := todoService.MoveTodo(todo, body.After, body.Before); err != nil {
+		respondUpdateError(c, err)
+		return
+	}
+
+	//Respond with moved todo
+	setETag(c, todo)
 	c.JSON(http.StatusOK, gin.H{
 		"todo": todo,
 	})
 }
 
+// respondUpdateError maps errors from replacing or patching a todo.
+func respondUpdateError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	var transitionErr *services.TransitionError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{"error": transitionErr.Error()})
+	case errors.Is(err, services.ErrInvalidPatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPatchConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedPatch):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrVersionConflict):
+		respondVersionConflict(c)
+	case errors.Is(err, services.ErrForbidden):
+		respondForbidden(c)
+	case errors.Is(err, services.ErrProjectNotFound):
+		respondProjectNotFound(c)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // ToDoDelete godoc
 // @Summary Delete a todo
-// @Description Удаление todo по id
+// @Description Удаление todo по id: по умолчанию todo переносится в корзину,
+// @Description с hard=true удаляется безвозвратно (в том числе из корзины)
 // @Tags todos
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Todo ID"
+// @Param hard query bool false "Delete permanently instead of moving to trash"
+// @Param If-Match header string false "ETag the deletion is based on"
 // @Success 204 {string} string "Successfully deleted"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Shared without the owner role"
 // @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 409 {object} map[string]string "Todo was modified concurrently"
+// @Failure 412 {object} map[string]string "Todo was modified"
 // @Router /todo/{id} [delete]
 func ToDoDelete(c *gin.Context) {
 	// Get param
 	id := c.Param("id")
-	todoService := services.NewTodoService()
+	todoService := newTodoService(c)
+
+	hard := false
+	if value := c.Query("hard"); value != "" {
+		var err error
+		if hard, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong hard format"})
+			return
+		}
+	}
+
+	// Conditional delete only goes through for the expected version
+	if c.GetHeader("If-Match") != "" {
+		find, remove := todoService.FindTodo, todoService.DeleteTodoVersion
+		if hard {
+			find, remove = todoService.FindTodoUnscoped, todoService.PurgeTodoVersion
+		}
+
+		todo, err := find(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ToDo doesn't exist"})
+			return
+		}
+
+		if !checkIfMatch(c, todo) {
+			return
+		}
+
+		if err := remove(todo); err != nil {
+			if errors.Is(err, services.ErrVersionConflict) {
+				respondVersionConflict(c)
+				return
+			}
+			if errors.Is(err, services.ErrForbidden) {
+				respondForbidden(c)
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+		return
+	}
 
 	// Delete todo using the service
-	if err := todoService.DeleteTodo(id); err != nil {
+	remove := todoService.DeleteTodo
+	if hard {
+		remove = todoService.PurgeTodo
+	}
+	if err := remove(id); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			respondForbidden(c)
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
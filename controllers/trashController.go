@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ToDoTrash godoc
+// @Summary List trashed todos
+// @Description Получение удаленных todo из корзины, список отдается страницами как и GET /todo
+// @Description Todo из корзины удаляются безвозвратно по истечении срока хранения
+// @Tags trash
+// @Accept  json
+// @Produce  json
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of todos to skip"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor"
+// @Param sort query string false "Sort fields, same as for GET /todo"
+// @Success 200 {object} todoPage "Page of trashed todos"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /todo/trash [get]
+func ToDoTrash(c *gin.Context) {
+	todoService := newTodoService(c)
+
+	pageRequest, err := bindPage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := todoService.ListTrash(pageRequest)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	setLinkHeader(c, page)
+	c.JSON(http.StatusOK, pageResponse(page))
+}
+
+// ToDoRestore godoc
+// @Summary Restore a todo
+// @Description Восстановление todo из корзины по id
+// @Tags trash
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Todo ID"
+// @Success 200 {object} models.ToDo "Restored todo"
+// @Failure 403 {object} map[string]string "Shared without the owner role"
+// @Failure 404 {object} map[string]string "Todo is not in trash"
+// @Router /todo/{id}/restore [post]
+func ToDoRestore(c *gin.Context) {
+	id := c.Param("id")
+	todoService := newTodoService(c)
+
+	todo, err := todoService.RestoreTodo(id)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			respondForbidden(c)
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"todo": todo,
+	})
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"errors"
+	"example/Studying/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type roleBody struct {
+	Role string `json:"role" binding:"required" enums:"viewer,editor,admin"`
+}
+
+// UserIndex godoc
+// @Summary List users
+// @Description Получение всех пользователей с их ролями, только для администраторов
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} models.User "Users"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Router /admin/users [get]
+func UserIndex(c *gin.Context) {
+	users, err := newAuthService().ListUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+	})
+}
+
+// UserSetRole godoc
+// @Summary Assign a role
+// @Description Назначение роли пользователю: viewer — только чтение, editor — чтение и изменения, admin — еще и назначение ролей
+// @Description Новая роль действует со следующего обновления access-токена; последнего администратора понизить нельзя
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Param role body roleBody true "Role"
+// @Success 200 {object} models.User "Successfully assigned"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 409 {object} map[string]string "Last admin"
+// @Router /admin/users/{id}/role [put]
+func UserSetRole(c *gin.Context) {
+	var body roleBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong data format, role is required"})
+		return
+	}
+
+	user, err := newAuthService().SetRole(c.Param("id"), body.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User doesn't exist"})
+		case errors.Is(err, services.ErrLastAdmin):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign role"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
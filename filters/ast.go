@@ -0,0 +1,67 @@
+// Package filters parses the filter expressions accepted by GET /todo, e.g.
+//
+//	title contains "report" and (status = false or created_at >= "2024-01-01")
+//
+// into an AST whose fields, operators and values have been checked against a
+// schema, so storage backends can translate it without further validation.
+package filters
+
+import "time"
+
+// Kind is the type of a filterable field.
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+	Bool
+	Time
+)
+
+// Schema lists the fields an expression may reference.
+type Schema map[string]Kind
+
+type Op string
+
+const (
+	Eq       Op = "="
+	NotEq    Op = "!="
+	Less     Op = "<"
+	LessEq   Op = "<="
+	Greater  Op = ">"
+	GreatEq  Op = ">="
+	Contains Op = "contains"
+)
+
+// Node is one of And, Or, Not or Comparison.
+type Node interface {
+	node()
+}
+
+type And struct {
+	Left, Right Node
+}
+
+type Or struct {
+	Left, Right Node
+}
+
+type Not struct {
+	Expr Node
+}
+
+// Comparison tests a field against a literal. Value holds a string, uint,
+// bool or time.Time matching the field's Kind.
+type Comparison struct {
+	Field string
+	Op    Op
+	Value interface{}
+}
+
+func (And) node()        {}
+func (Or) node()         {}
+func (Not) node()        {}
+func (Comparison) node() {}
+
+// timeLayouts are accepted for Time fields, most specific first.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
@@ -0,0 +1,107 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// SyntaxError reports a malformed expression and where it went wrong.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Invalid filter at position %d: %s", e.Pos, e.Msg)
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		ch := rune(input[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case ch == '"':
+			text, end, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokString, text, i})
+			i = end
+		case strings.ContainsRune("=!<>", ch):
+			op := string(ch)
+			if i+1 < len(input) && input[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &SyntaxError{i, `expected "!="`}
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		case ch == '-' || unicode.IsDigit(ch):
+			start := i
+			for i++; i < len(input) && (unicode.IsDigit(rune(input[i])) || input[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{tokNumber, input[start:i], start})
+		case ch == '_' || unicode.IsLetter(ch):
+			start := i
+			for ; i < len(input) && (input[i] == '_' || unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i]))); i++ {
+			}
+			tokens = append(tokens, token{tokIdent, input[start:i], start})
+		default:
+			return nil, &SyntaxError{i, fmt.Sprintf("unexpected character %q", ch)}
+		}
+	}
+
+	return append(tokens, token{tokEOF, "", len(input)}), nil
+}
+
+// lexString reads a double quoted literal starting at input[start]; a
+// backslash escapes the next character.
+func lexString(input string, start int) (string, int, error) {
+	var b strings.Builder
+
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 == len(input) {
+				return "", 0, &SyntaxError{i, "unterminated string"}
+			}
+			i++
+			b.WriteByte(input[i])
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(input[i])
+		}
+	}
+
+	return "", 0, &SyntaxError{start, "unterminated string"}
+}
@@ -0,0 +1,219 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxLength caps expressions so a request cannot make the parser or the
+// generated SQL arbitrarily large.
+const MaxLength = 1024
+
+// Parse turns expr into an AST, checking every field and value against
+// schema. Keywords (and, or, not, contains, true, false) are case insensitive.
+//
+//	expr       = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expr ")" | comparison
+//	comparison = field ( "=" | "!=" | "<" | "<=" | ">" | ">=" | "contains" ) value
+//	value      = string | number | "true" | "false"
+func Parse(expr string, schema Schema) (Node, error) {
+	if len(expr) > MaxLength {
+		return nil, &SyntaxError{MaxLength, "expression is too long"}
+	}
+
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, schema: schema}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &SyntaxError{tok.pos, fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	return node, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	schema Schema
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseFactor() (Node, error) {
+	if p.keyword("not") {
+		expr, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return Not{expr}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokRParen {
+			return nil, &SyntaxError{tok.pos, `expected ")"`}
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Node, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokIdent {
+		return nil, &SyntaxError{fieldTok.pos, "expected a field name"}
+	}
+
+	field := strings.ToLower(fieldTok.text)
+	kind, ok := p.schema[field]
+	if !ok {
+		return nil, &SyntaxError{fieldTok.pos, fmt.Sprintf("unknown field %q", fieldTok.text)}
+	}
+
+	opTok := p.next()
+	var op Op
+	switch {
+	case opTok.kind == tokOp:
+		op = Op(opTok.text)
+	case opTok.kind == tokIdent && strings.EqualFold(opTok.text, "contains"):
+		op = Contains
+	default:
+		return nil, &SyntaxError{opTok.pos, "expected an operator"}
+	}
+
+	if !allowed(kind, op) {
+		return nil, &SyntaxError{opTok.pos, fmt.Sprintf("operator %q cannot be used with %s", op, field)}
+	}
+
+	valueTok := p.next()
+	value, err := literal(valueTok, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return Comparison{Field: field, Op: op, Value: value}, nil
+}
+
+func allowed(kind Kind, op Op) bool {
+	switch kind {
+	case Bool:
+		return op == Eq || op == NotEq
+	case String:
+		return true
+	default:
+		return op != Contains
+	}
+}
+
+// literal converts a value token into the Go type used for kind.
+func literal(tok token, kind Kind) (interface{}, error) {
+	fail := func(what string) error {
+		return &SyntaxError{tok.pos, fmt.Sprintf("expected %s, got %q", what, tok.text)}
+	}
+
+	switch kind {
+	case String:
+		if tok.kind != tokString {
+			return nil, fail("a quoted string")
+		}
+		return tok.text, nil
+	case Number:
+		if tok.kind != tokNumber {
+			return nil, fail("a number")
+		}
+		n, err := strconv.ParseUint(tok.text, 10, 64)
+		if err != nil {
+			return nil, fail("a whole number")
+		}
+		return uint(n), nil
+	case Bool:
+		if tok.kind == tokIdent && strings.EqualFold(tok.text, "true") {
+			return true, nil
+		}
+		if tok.kind == tokIdent && strings.EqualFold(tok.text, "false") {
+			return false, nil
+		}
+		return nil, fail("true or false")
+	case Time:
+		if tok.kind == tokString {
+			for _, layout := range timeLayouts {
+				if t, err := time.Parse(layout, tok.text); err == nil {
+					return t, nil
+				}
+			}
+		}
+		return nil, fail(`a quoted date like "2024-01-31" or RFC 3339 time`)
+	}
+
+	return nil, fail("a value")
+}
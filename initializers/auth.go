@@ -0,0 +1,78 @@
+package initializers
+
+import (
+	"example/Studying/jwt"
+	"example/Studying/services"
+	"log"
+	"os"
+)
+
+// minSecretLength is the shortest JWT_SECRET accepted: HS256 is only as
+// strong as its secret.
+const minSecretLength = 32
+
+// placeholderSecret is the example JWT_SECRET docker-compose.yml used to
+// ship with. It is long enough, but public, so it is refused.
+const placeholderSecret = "change-me-to-a-random-32-byte-secret"
+
+// Auth says how access tokens are signed and checked.
+var Auth = services.AuthConfig{
+	AccessTTL:  services.DefaultAccessTTL,
+	RefreshTTL: services.DefaultRefreshTTL,
+}
+
+// LoadAuth sets up access tokens. With JWT_PRIVATE_KEY, the path of a PEM
+// RSA key, tokens are signed RS256 under the key id JWT_KEY_ID; otherwise
+// they are signed HS256 with JWT_SECRET. Tokens signed with JWT_SECRET, or
+// with any key in the JWKS file JWT_JWKS_FILE, are accepted as well, which
+// keeps tokens signed with the previous key working during a rotation.
+// ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL say how long tokens last.
+func LoadAuth() {
+	Auth.AccessTTL = durationEnv("ACCESS_TOKEN_TTL", services.DefaultAccessTTL)
+	Auth.RefreshTTL = durationEnv("REFRESH_TOKEN_TTL", services.DefaultRefreshTTL)
+	if Auth.AccessTTL == 0 || Auth.RefreshTTL == 0 {
+		log.Fatal("ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL can't be 0")
+	}
+
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	if len(secret) > 0 && len(secret) < minSecretLength {
+		log.Fatalf("JWT_SECRET have to be at least %d bytes", minSecretLength)
+	}
+	if string(secret) == placeholderSecret {
+		log.Fatal("JWT_SECRET is the example value, set a random secret")
+	}
+
+	var keys []jwt.KeySet
+	switch path := os.Getenv("JWT_PRIVATE_KEY"); {
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read JWT_PRIVATE_KEY: %s", err)
+		}
+		key, err := jwt.ParseRSAPrivateKey(data)
+		if err != nil {
+			log.Fatalf("Invalid JWT_PRIVATE_KEY: %s", err)
+		}
+
+		keyID := os.Getenv("JWT_KEY_ID")
+		if keyID == "" {
+			keyID = "default"
+		}
+		Auth.Signer = jwt.NewRS256Signer(key, keyID)
+		keys = append(keys, jwt.StaticKeys{keyID: &key.PublicKey})
+	case len(secret) > 0:
+		Auth.Signer = jwt.NewHS256Signer(secret)
+	default:
+		log.Fatal("JWT_SECRET or JWT_PRIVATE_KEY is required")
+	}
+
+	if path := os.Getenv("JWT_JWKS_FILE"); path != "" {
+		jwks, err := jwt.OpenJWKSFile(path)
+		if err != nil {
+			log.Fatalf("Invalid JWT_JWKS_FILE: %s", err)
+		}
+		keys = append(keys, jwks)
+	}
+
+	Auth.Verifier = jwt.NewVerifier(secret, keys...)
+}
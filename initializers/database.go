@@ -1,18 +1,211 @@
 package initializers
 
 import (
+	"database/sql"
+	"example/Studying/models"
+	"example/Studying/ranking"
+	"example/Studying/repositories"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
+// sqliteDriver is go-sqlite3 with a LOWER that folds every letter rather
+// than only ASCII ones, so searches and contains filters ignore the case of
+// "Отчет" the same way on SQLite as on Postgres and in memory.
+const sqliteDriver = "sqlite3_unicode"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("lower", strings.ToLower, true)
+		},
+	})
+}
+
 func ConnectToDB() {
 	var err error
+	DB, err = gorm.Open(dialector(), &gorm.Config{})
+
+	if err != nil {
+		log.Fatal("Failed to connect to database")
+	}
+
+	if DB.Dialector.Name() == "sqlite" {
+		// SQLite serialises writers anyway, and a single connection keeps a
+		// ":memory:" database from being recreated for every pooled connection.
+		sqlDB, err := DB.DB()
+		if err != nil {
+			log.Fatal("Failed to connect to database")
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+}
+
+// MigrateDB creates or updates the tables for every model.
+func MigrateDB() error {
+	if err := DB.AutoMigrate(&models.ToDo{}, &models.Tag{}, &models.Subtask{}, &models.Comment{}, &models.Attachment{}, &models.Project{}, &models.Series{}, &models.User{}, &models.Session{}, &models.APIKey{}, &models.Share{}); err != nil {
+		return err
+	}
+	if err := migrateRanks(); err != nil {
+		return err
+	}
+	if err := migrateStates(); err != nil {
+		return err
+	}
+	if err := migrateRoles(); err != nil {
+		return err
+	}
+	if err := migrateTags(); err != nil {
+		return err
+	}
+	if err := migrateOwners(); err != nil {
+		return err
+	}
+	if err := migrateCommentAuthors(); err != nil {
+		return err
+	}
+
+	if DB.Dialector.Name() == "postgres" {
+		return migrateSearch()
+	}
+
+	return nil
+}
+
+// migrateSearch adds the weighted full-text index used by GET /todo/search.
+// The column is generated, so Postgres keeps it in sync with title and body.
+func migrateSearch() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE to_dos ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(body, '')), 'B')
+			) STORED`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("CREATE INDEX IF NOT EXISTS idx_to_dos_search_vector ON to_dos USING GIN (search_vector)").Error
+	})
+}
+
+// migrateRanks gives todos created before manual ordering existed a rank,
+// appending them in creation order after the todos that already have one.
+func migrateRanks() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var last sql.NullString
+		if err := tx.Unscoped().Model(&models.ToDo{}).Select("MAX(rank)").Scan(&last).Error; err != nil {
+			return err
+		}
+
+		var ids []uint
+		if err := tx.Unscoped().Model(&models.ToDo{}).Where("rank = ''").Order("id").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		rank := last.String
+		for _, id := range ids {
+			rank = ranking.After(rank)
+			if err := tx.Unscoped().Model(&models.ToDo{}).Where("id = ?", id).UpdateColumn("rank", rank).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateStates derives the state of todos created before the workflow
+// existed from their status. When they were completed is unknown, so the
+// last update stands in for it.
+func migrateStates() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.ToDo{}).
+			Where("status = ? AND state <> ?", true, models.StateDone).
+			UpdateColumns(map[string]interface{}{"state": models.StateDone, "completed_at": gorm.Expr("updated_at")}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&models.ToDo{}).
+			Where("status = ? AND state = ?", false, models.StateDone).
+			UpdateColumn("state", models.StateBacklog).Error
+	})
+}
+
+// migrateRoles makes the oldest user an admin when there is none, so that
+// accounts created before roles existed still have someone who can assign
+// them.
+func migrateRoles() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return nil
+		}
+
+		var ids []uint
+		if err := tx.Model(&models.User{}).Order("id").Limit(1).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", ids[0]).Update("role", models.RoleAdmin).Error
+	})
+}
+
+// migrateTags drops the index that kept tag names unique across users;
+// names are unique per owner now.
+func migrateTags() error {
+	if !DB.Migrator().HasIndex(&models.Tag{}, "idx_tags_name") {
+		return nil
+	}
+
+	return DB.Migrator().DropIndex(&models.Tag{}, "idx_tags_name")
+}
+
+// migrateOwners gives the todos, series, projects and tags created before
+// there were users to the first admin, so that they don't stay hidden from
+// everybody. Without an admin yet, the first user to register claims them
+// instead, see repositories.ClaimOwnerless.
+func migrateOwners() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var admins []uint
+		if err := tx.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Order("id").Limit(1).Pluck("id", &admins).Error; err != nil || len(admins) == 0 {
+			return err
+		}
+
+		return repositories.ClaimOwnerless(tx, admins[0])
+	})
+}
+
+// migrateCommentAuthors links comments written before authors were kept by
+// id to the user with the username they were written under.
+func migrateCommentAuthors() error {
+	return DB.Model(&models.Comment{}).
+		Where("author_id = 0").
+		Where("EXISTS (?)", DB.Model(&models.User{}).Select("1").Where("users.username = comments.author")).
+		UpdateColumn("author_id", DB.Model(&models.User{}).Select("id").Where("users.username = comments.author")).Error
+}
+
+func dialector() gorm.Dialector {
+	if os.Getenv("DB_DRIVER") == "sqlite" {
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = "todo.db"
+		}
+
+		return &sqlite.Dialector{DriverName: sqliteDriver, DSN: path}
+	}
+
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_USER"),
@@ -20,9 +213,6 @@ func ConnectToDB() {
 		os.Getenv("DB_NAME"),
 		os.Getenv("DB_PORT"),
 	)
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 
-	if err != nil {
-		log.Fatal("Failed to connect to database")
-	}
+	return postgres.Open(dsn)
 }
@@ -0,0 +1,52 @@
+package initializers
+
+import (
+	"example/Studying/jobs"
+	"example/Studying/services"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	defaultTrashRetention     = 30 * 24 * time.Hour
+	defaultTrashPurgeInterval = time.Hour
+)
+
+// StartJobs launches the background jobs. TRASH_RETENTION (e.g. "720h", "0"
+// disables purging) and TRASH_PURGE_INTERVAL, which can't be zero, tune the
+// trash purge.
+func StartJobs() {
+	retention := durationEnv("TRASH_RETENTION", defaultTrashRetention)
+	interval := intervalEnv("TRASH_PURGE_INTERVAL", defaultTrashPurgeInterval)
+
+	if retention > 0 {
+		attachments := services.NewAttachmentService(AttachmentRepository, BlobStore, AttachmentMaxSize)
+		jobs.StartTrashPurge(services.NewTodoService(TodoRepository).WithAttachments(attachments), retention, interval)
+	}
+}
+
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Fatalf("Invalid %s %q", name, value)
+	}
+
+	return d
+}
+
+// intervalEnv is durationEnv for how often a job runs, which has to be
+// longer than zero.
+func intervalEnv(name string, fallback time.Duration) time.Duration {
+	d := durationEnv(name, fallback)
+	if d == 0 {
+		log.Fatalf("Invalid %s %q, it has to be longer than zero", name, os.Getenv(name))
+	}
+
+	return d
+}
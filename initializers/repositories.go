@@ -0,0 +1,69 @@
+package initializers
+
+import (
+	"example/Studying/repositories"
+	"log"
+	"os"
+)
+
+var (
+	TodoRepository    repositories.TodoRepository
+	TagRepository     repositories.TagRepository
+	SubtaskRepository repositories.SubtaskRepository
+	CommentRepository repositories.CommentRepository
+	// AttachmentRepository keeps attachment metadata, see BlobStore.
+	AttachmentRepository repositories.AttachmentRepository
+	ProjectRepository    repositories.ProjectRepository
+	SeriesRepository     repositories.SeriesRepository
+	UserRepository       repositories.UserRepository
+	ShareRepository      repositories.ShareRepository
+)
+
+// ConnectToStorage picks the storage backend from DB_DRIVER. Postgres stays
+// the default so existing deployments keep working without changes.
+func ConnectToStorage() {
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "postgres":
+		ConnectToDB()
+		InitRepositories()
+	case "sqlite":
+		// A SQLite file (or ":memory:") ships with the binary, so there is no
+		// separate migration step to run before serving.
+		ConnectToDB()
+		if err := MigrateDB(); err != nil {
+			log.Fatalf("Failed to migrate database: %s", err)
+		}
+		InitRepositories()
+	case "memory":
+		InitMemoryRepositories()
+	default:
+		log.Fatalf("Unknown DB_DRIVER %q", driver)
+	}
+}
+
+func InitRepositories() {
+	TodoRepository = repositories.NewGormTodoRepository(DB)
+	TagRepository = repositories.NewGormTagRepository(DB)
+	SubtaskRepository = repositories.NewGormSubtaskRepository(DB)
+	CommentRepository = repositories.NewGormCommentRepository(DB)
+	AttachmentRepository = repositories.NewGormAttachmentRepository(DB)
+	ProjectRepository = repositories.NewGormProjectRepository(DB)
+	SeriesRepository = repositories.NewGormSeriesRepository(DB)
+	UserRepository = repositories.NewGormUserRepository(DB)
+	ShareRepository = repositories.NewGormShareRepository(DB)
+}
+
+// InitMemoryRepositories sets up repositories that keep everything in the
+// process, for running without a database.
+func InitMemoryRepositories() {
+	todos := repositories.NewMemoryTodoRepository()
+	TodoRepository = todos
+	TagRepository = repositories.NewMemoryTagRepository(todos)
+	SubtaskRepository = repositories.NewMemorySubtaskRepository(todos)
+	CommentRepository = repositories.NewMemoryCommentRepository(todos)
+	AttachmentRepository = repositories.NewMemoryAttachmentRepository(todos)
+	ProjectRepository = repositories.NewMemoryProjectRepository(todos)
+	SeriesRepository = repositories.NewMemorySeriesRepository(todos)
+	UserRepository = repositories.NewMemoryUserRepository()
+	ShareRepository = repositories.NewMemoryShareRepository(todos)
+}
@@ -0,0 +1,60 @@
+package initializers
+
+import (
+	"example/Studying/services"
+	"example/Studying/storage"
+	"log"
+	"os"
+	"strconv"
+)
+
+var (
+	// BlobStore keeps the content of attachments.
+	BlobStore storage.BlobStore
+	// AttachmentMaxSize is the largest attachment accepted, in bytes.
+	AttachmentMaxSize int64 = services.DefaultAttachmentMaxSize
+)
+
+// ConnectToBlobStore picks where attachments are stored from BLOB_STORE:
+// "local" (the default) keeps them in BLOB_DIR, "s3" in the S3_BUCKET of
+// S3_ENDPOINT, signed with S3_ACCESS_KEY and S3_SECRET_KEY for S3_REGION.
+// ATTACHMENT_MAX_SIZE limits uploads, in bytes.
+func ConnectToBlobStore() {
+	switch kind := os.Getenv("BLOB_STORE"); kind {
+	case "", "local":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "attachments"
+		}
+
+		store, err := storage.NewLocalStore(dir)
+		if err != nil {
+			log.Fatalf("Failed to open blob directory: %s", err)
+		}
+		BlobStore = store
+	case "s3":
+		config := storage.S3Config{
+			Endpoint: os.Getenv("S3_ENDPOINT"),
+			Region:   os.Getenv("S3_REGION"),
+			Bucket:   os.Getenv("S3_BUCKET"),
+			Credentials: storage.Credentials{
+				AccessKey: os.Getenv("S3_ACCESS_KEY"),
+				SecretKey: os.Getenv("S3_SECRET_KEY"),
+			},
+		}
+		if config.Endpoint == "" || config.Bucket == "" {
+			log.Fatal("S3_ENDPOINT and S3_BUCKET are required for BLOB_STORE=s3")
+		}
+		BlobStore = storage.NewS3Store(config)
+	default:
+		log.Fatalf("Unknown BLOB_STORE %q", kind)
+	}
+
+	if value := os.Getenv("ATTACHMENT_MAX_SIZE"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || size <= 0 {
+			log.Fatalf("Invalid ATTACHMENT_MAX_SIZE %q", value)
+		}
+		AttachmentMaxSize = size
+	}
+}
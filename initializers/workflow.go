@@ -0,0 +1,25 @@
+package initializers
+
+import (
+	"example/Studying/services"
+	"log"
+	"os"
+)
+
+// Workflow is the state machine todos follow.
+var Workflow = services.DefaultWorkflow()
+
+// LoadWorkflow replaces the default workflow with WORKFLOW_TRANSITIONS when
+// it is set, e.g. "backlog>in_progress,done;in_progress>done,backlog;done>backlog".
+func LoadWorkflow() {
+	spec := os.Getenv("WORKFLOW_TRANSITIONS")
+	if spec == "" {
+		return
+	}
+
+	workflow, err := services.ParseWorkflow(spec)
+	if err != nil {
+		log.Fatalf("Invalid WORKFLOW_TRANSITIONS: %s", err)
+	}
+	Workflow = workflow
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"example/Studying/services"
+	"log"
+	"time"
+)
+
+// StartTrashPurge purges todos trashed longer than retention once right away
+// and then every interval, until the returned stop function is called.
+func StartTrashPurge(todoService *services.TodoService, retention, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	purge := func() {
+		purged, err := todoService.PurgeTrash(retention)
+		if err != nil {
+			log.Printf("Failed to purge trash: %s", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("Purged %d todos from trash", purged)
+		}
+	}
+
+	go func() {
+		defer ticker.Stop()
+
+		purge()
+		for {
+			select {
+			case <-ticker.C:
+				purge()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
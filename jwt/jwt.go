@@ -0,0 +1,194 @@
+// Package jwt issues and checks the signed access tokens of the API: JSON
+// Web Tokens in compact form, signed with HS256 (a shared secret) or RS256
+// (an RSA key pair). Keys that verify RS256 tokens come from a KeySet, such
+// as a JWKS file, so signing keys can be rotated without invalidating the
+// tokens already handed out.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+var (
+	// ErrInvalidToken means the token is malformed, signed with an algorithm
+	// or key that is not accepted, or its signature does not match.
+	ErrInvalidToken = errors.New("jwt: invalid token")
+	// ErrExpired means the token was valid but its exp is in the past.
+	ErrExpired = errors.New("jwt: token expired")
+)
+
+// Claims are the claims the API puts in its tokens. Subject is the user id
+// and Role the role the user had when the token was issued.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Username  string `json:"preferred_username,omitempty"`
+	Role      string `json:"role,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Signer signs tokens with one key.
+type Signer struct {
+	header header
+	secret []byte
+	key    *rsa.PrivateKey
+}
+
+// NewHS256Signer signs with an HMAC-SHA256 secret.
+func NewHS256Signer(secret []byte) *Signer {
+	return &Signer{header: header{Algorithm: HS256, Type: "JWT"}, secret: secret}
+}
+
+// NewRS256Signer signs with an RSA private key. keyID goes into the kid
+// header so that verifiers can pick the matching public key.
+func NewRS256Signer(key *rsa.PrivateKey, keyID string) *Signer {
+	return &Signer{header: header{Algorithm: RS256, Type: "JWT", KeyID: keyID}, key: key}
+}
+
+// Sign returns claims as a signed compact token.
+func (s *Signer) Sign(claims Claims) (string, error) {
+	head, err := encode(s.header)
+	if err != nil {
+		return "", err
+	}
+	payload, err := encode(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := head + "." + payload
+	var signature []byte
+	if s.key != nil {
+		digest := sha256.Sum256([]byte(input))
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:]); err != nil {
+			return "", err
+		}
+	} else {
+		signature = mac(s.secret, input)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// KeySet looks up the RSA public key published under a key id.
+type KeySet interface {
+	PublicKey(keyID string) (*rsa.PublicKey, bool)
+}
+
+// StaticKeys is a KeySet that never changes.
+type StaticKeys map[string]*rsa.PublicKey
+
+func (k StaticKeys) PublicKey(keyID string) (*rsa.PublicKey, bool) {
+	key, ok := k[keyID]
+	return key, ok
+}
+
+// Verifier checks tokens. It accepts HS256 tokens only if it has a secret
+// and RS256 tokens only if one of its key sets holds the token's kid, so a
+// token can't pick an algorithm the verifier was not set up for.
+type Verifier struct {
+	secret []byte
+	keys   []KeySet
+}
+
+// NewVerifier accepts tokens signed with secret, if it is not empty, or by
+// a key in any of keys.
+func NewVerifier(secret []byte, keys ...KeySet) *Verifier {
+	return &Verifier{secret: secret, keys: keys}
+}
+
+// Verify checks the signature of token and that it has not expired at now,
+// and returns its claims.
+func (v *Verifier) Verify(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var head header
+	if err := decode(parts[0], &head); err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	input := parts[0] + "." + parts[1]
+	if !v.valid(head, input, signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decode(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, ErrInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+
+	return &claims, nil
+}
+
+func (v *Verifier) valid(head header, input string, signature []byte) bool {
+	switch head.Algorithm {
+	case HS256:
+		return len(v.secret) > 0 && hmac.Equal(signature, mac(v.secret, input))
+	case RS256:
+		digest := sha256.Sum256([]byte(input))
+		for _, keys := range v.keys {
+			if key, ok := keys.PublicKey(head.KeyID); ok {
+				return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+			}
+		}
+	}
+
+	return false
+}
+
+func mac(secret []byte, input string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(input))
+	return h.Sum(nil)
+}
+
+func encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decode(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// ParseRSAPrivateKey reads a PEM encoded RSA private key in PKCS #1
+// ("RSA PRIVATE KEY") or PKCS #8 ("PRIVATE KEY") form.
+func ParseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+			return rsaKey, nil
+		}
+		return nil, errors.New("jwt: private key is not an RSA key")
+	}
+
+	return nil, fmt.Errorf("jwt: unexpected PEM block %q", block.Type)
+}
+
+// jwk is a JSON Web Key; only RSA signing keys are used.
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use,omitempty"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// ParseJWKS reads a JSON Web Key Set and returns its RSA signing keys by
+// key id. Keys of other types or uses are skipped.
+func ParseJWKS(data []byte) (StaticKeys, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: invalid JWKS: %w", err)
+	}
+
+	keys := StaticKeys{}
+	for _, key := range set.Keys {
+		if key.KeyType != "RSA" || key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		n, errN := base64.RawURLEncoding.DecodeString(key.N)
+		e, errE := base64.RawURLEncoding.DecodeString(key.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwt: invalid RSA key %q in JWKS", key.KeyID)
+		}
+
+		keys[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+// MarshalJWKS writes keys as a JSON Web Key Set.
+func MarshalJWKS(keys StaticKeys) ([]byte, error) {
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{Keys: []jwk{}}
+	for id, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			KeyType: "RSA",
+			KeyID:   id,
+			Use:     "sig",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	return json.Marshal(set)
+}
+
+// JWKSFile is a KeySet read from a JWKS file. Every lookup reads the file
+// again if it changed, so keys can be published and revoked by rewriting the
+// file while the server runs.
+type JWKSFile struct {
+	path string
+
+	mu      sync.Mutex
+	keys    StaticKeys
+	modTime time.Time
+}
+
+// OpenJWKSFile reads the key set at path.
+func OpenJWKSFile(path string) (*JWKSFile, error) {
+	file := &JWKSFile{path: path}
+	if err := file.reload(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (f *JWKSFile) PublicKey(keyID string) (*rsa.PublicKey, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// A broken file keeps the keys read last; the next lookup tries again.
+	f.reload()
+
+	key, ok := f.keys[keyID]
+	return key, ok
+}
+
+// reload reads the file if it changed since it was last read.
+func (f *JWKSFile) reload() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if f.keys != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	f.keys, f.modTime = keys, info.ModTime()
+	return nil
+}
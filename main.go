@@ -13,20 +13,20 @@ import (
 
 func init() {
 	initializers.LoadEnvVariables()
-	initializers.ConnectToDB()
+	initializers.LoadWorkflow()
+	initializers.LoadAuth()
+	initializers.ConnectToStorage()
+	initializers.ConnectToBlobStore()
 }
 
 func main() {
 	r := gin.Default()
 
-	r.POST("/todo", controllers.ToDoCreate)
-	r.PUT("/todo/:id", controllers.ToDoUpdate)
-	r.DELETE("/todo/:id", controllers.ToDoDelete)
-
-	r.GET("/todo", controllers.ToDoIndex)
-	r.GET("/todo/:id", controllers.ToDoShow)
+	controllers.Routes(r)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
+	initializers.StartJobs()
+
 	r.Run()
 }
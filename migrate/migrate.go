@@ -2,7 +2,7 @@ package main
 
 import (
 	"example/Studying/initializers"
-	"example/Studying/models"
+	"log"
 )
 
 func init() {
@@ -11,5 +11,7 @@ func init() {
 }
 
 func main() {
-	initializers.DB.AutoMigrate(&models.ToDo{})
+	if err := initializers.MigrateDB(); err != nil {
+		log.Fatalf("Failed to migrate database: %s", err)
+	}
 }
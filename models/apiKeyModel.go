@@ -0,0 +1,59 @@
+package models
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Scope is what an API key may do.
+type Scope string
+
+const (
+	// ScopeRead allows requests that only read: GET, HEAD and OPTIONS.
+	ScopeRead Scope = "read"
+	// ScopeWrite allows every other request.
+	ScopeWrite Scope = "write"
+)
+
+// ParseScope returns the scope called name.
+func ParseScope(name string) (Scope, bool) {
+	switch scope := Scope(name); scope {
+	case ScopeRead, ScopeWrite:
+		return scope, true
+	}
+
+	return "", false
+}
+
+// APIKey lets scripts call the API as a user without logging in. The key
+// itself is shown once when it is created; only its SHA-256 hash is stored,
+// and Prefix, its first characters, tells keys apart in listings.
+type APIKey struct {
+	ID      uint    `gorm:"primarykey"`
+	UserID  uint    `gorm:"not null;index" json:"-"`
+	Name    string  `gorm:"size:100;not null"`
+	Prefix  string  `gorm:"size:12;not null"`
+	KeyHash string  `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes  []Scope `gorm:"serializer:json" swaggertype:"array,string" enums:"read,write"`
+	// ExpiresAt is nil for keys that do not expire.
+	ExpiresAt *time.Time
+	// LastUsedAt is updated at most once a minute.
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// ScopeFor returns the scope a request with method needs.
+func ScopeFor(method string) Scope {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead
+	}
+
+	return ScopeWrite
+}
+
+// Allows reports whether the key's scopes cover a request with method.
+func (k *APIKey) Allows(method string) bool {
+	return slices.Contains(k.Scopes, ScopeFor(method))
+}
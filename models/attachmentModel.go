@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Attachment describes a file attached to a todo. The content itself lives
+// in a storage.BlobStore under Key.
+type Attachment struct {
+	ID          uint   `gorm:"primarykey"`
+	TodoID      uint   `gorm:"not null;index"`
+	Filename    string `gorm:"size:255;not null"`
+	ContentType string `gorm:"size:255;not null"`
+	Size        int64  `gorm:"not null"`
+	Key         string `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Comment is a message in the discussion of a todo. Body is markdown, stored
+// as written; rendering it is up to clients. Author is the username the
+// comment was written under, AuthorID the user who wrote it.
+type Comment struct {
+	ID        uint   `gorm:"primarykey"`
+	TodoID    uint   `gorm:"not null;index"`
+	AuthorID  uint   `gorm:"not null;default:0;index"`
+	Author    string `gorm:"size:100;not null"`
+	Body      string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Priority is how urgent a todo is. It is stored as a number so that it
+// sorts, and exchanged as its name in JSON.
+type Priority uint
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// ParsePriority returns the priority called name; an empty name is none.
+func ParsePriority(name string) (Priority, bool) {
+	if name == "" {
+		return PriorityNone, true
+	}
+	for i, priorityName := range priorityNames {
+		if name == priorityName {
+			return Priority(i), true
+		}
+	}
+
+	return PriorityNone, false
+}
+
+// PriorityNames lists the valid priorities from lowest to highest.
+func PriorityNames() []string {
+	return append([]string(nil), priorityNames...)
+}
+
+func (p Priority) String() string {
+	if int(p) < len(priorityNames) {
+		return priorityNames[p]
+	}
+
+	return fmt.Sprintf("Priority(%d)", uint(p))
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	if int(p) >= len(priorityNames) {
+		return nil, fmt.Errorf("invalid priority %d", uint(p))
+	}
+
+	return []byte(priorityNames[p]), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, ok := ParsePriority(string(text))
+	if !ok {
+		return fmt.Errorf("invalid priority %q", text)
+	}
+
+	*p = parsed
+	return nil
+}
+
+func (p Priority) Value() (driver.Value, error) {
+	return int64(p), nil
+}
+
+func (p *Priority) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case int64:
+		*p = Priority(value)
+	case nil:
+		*p = PriorityNone
+	default:
+		return fmt.Errorf("cannot scan %T into Priority", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Project is a list that groups todos. A todo belongs to at most one
+// project; todos without one are in the inbox.
+type Project struct {
+	ID uint `gorm:"primarykey"`
+	// OwnerID is the user the project belongs to. Projects created before
+	// there were users have 0.
+	OwnerID     uint   `gorm:"not null;default:0;index"`
+	Name        string `gorm:"size:100;not null"`
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
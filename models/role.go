@@ -0,0 +1,59 @@
+package models
+
+import "slices"
+
+// Role is what a user may do, as a set of permissions.
+type Role string
+
+const (
+	// RoleViewer can only read.
+	RoleViewer Role = "viewer"
+	// RoleEditor can read and change todos, tags and projects. It is the
+	// role new users get.
+	RoleEditor Role = "editor"
+	// RoleAdmin can also assign roles.
+	RoleAdmin Role = "admin"
+)
+
+// Permission is something a handler requires of the caller's role.
+type Permission string
+
+const (
+	PermissionRead        Permission = "read"
+	PermissionWrite       Permission = "write"
+	PermissionManageUsers Permission = "manage_users"
+)
+
+var roles = []Role{RoleViewer, RoleEditor, RoleAdmin}
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {PermissionRead},
+	RoleEditor: {PermissionRead, PermissionWrite},
+	RoleAdmin:  {PermissionRead, PermissionWrite, PermissionManageUsers},
+}
+
+// ParseRole returns the role called name.
+func ParseRole(name string) (Role, bool) {
+	for _, role := range roles {
+		if Role(name) == role {
+			return role, true
+		}
+	}
+
+	return "", false
+}
+
+// RoleNames lists the valid roles from least to most powerful.
+func RoleNames() []string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+
+	return names
+}
+
+// Can reports whether the role has permission.
+func (r Role) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[r], permission)
+}
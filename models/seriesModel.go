@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Series is a recurring todo: the rule its occurrences follow and the fields
+// new occurrences get. Each occurrence is an ordinary todo that points back
+// at its series, and only one of them, the latest, is ever open at a time.
+type Series struct {
+	ID uint `gorm:"primarykey"`
+	// OwnerID is the user whose todos the occurrences are.
+	OwnerID uint `gorm:"not null;default:0;index"`
+	// RRule is the recurrence rule in canonical form, empty once the series
+	// has ended.
+	RRule string
+	// Start anchors the rule: it is the due date of the first occurrence.
+	Start time.Time
+	// LatestID is the newest occurrence. Completing it creates the next one.
+	LatestID  uint
+	Title     string
+	Body      string
+	Priority  Priority `gorm:"not null;default:0" swaggertype:"string" enums:"none,low,medium,high,urgent"`
+	ProjectID *uint
+	// StartBefore is how long before its due date an occurrence starts, nil
+	// if occurrences have no start date.
+	StartBefore *time.Duration `swaggertype:"integer"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
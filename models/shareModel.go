@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// ShareRole is how much a share lets its user do with the shared todos.
+type ShareRole string
+
+const (
+	// ShareViewer can see the todos with their subtasks, comments and
+	// attachments.
+	ShareViewer ShareRole = "viewer"
+	// ShareEditor can also change them.
+	ShareEditor ShareRole = "editor"
+	// ShareOwner can also delete, restore and share them, like their owner.
+	ShareOwner ShareRole = "owner"
+)
+
+var shareRoles = []ShareRole{ShareViewer, ShareEditor, ShareOwner}
+
+// ParseShareRole returns the share role called name.
+func ParseShareRole(name string) (ShareRole, bool) {
+	if slices.Contains(shareRoles, ShareRole(name)) {
+		return ShareRole(name), true
+	}
+
+	return "", false
+}
+
+// Includes reports whether r allows everything other does. The empty role,
+// no access, includes nothing but itself.
+func (r ShareRole) Includes(other ShareRole) bool {
+	return slices.Index(shareRoles, r) >= slices.Index(shareRoles, other)
+}
+
+// Share gives the user with UserID access to a todo of OwnerID, or with
+// ProjectID to all of OwnerID's todos in that project, including ones added
+// later. Exactly one of TodoID and ProjectID is set.
+type Share struct {
+	ID        uint      `gorm:"primarykey"`
+	OwnerID   uint      `gorm:"not null;uniqueIndex:idx_shares_project_user,priority:2"`
+	TodoID    *uint     `gorm:"uniqueIndex:idx_shares_todo_user,priority:1"`
+	ProjectID *uint     `gorm:"uniqueIndex:idx_shares_project_user,priority:1"`
+	UserID    uint      `gorm:"not null;index;uniqueIndex:idx_shares_todo_user,priority:2;uniqueIndex:idx_shares_project_user,priority:3"`
+	Role      ShareRole `gorm:"size:10;not null" enums:"viewer,editor,owner"`
+	// User is who the todos are shared with; it is filled in for listings.
+	User      *User `gorm:"-"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
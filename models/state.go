@@ -0,0 +1,38 @@
+package models
+
+// State is where a todo is in its workflow. Which states a todo may move
+// between is decided by services.Workflow; Status mirrors whether the state
+// is StateDone.
+type State string
+
+const (
+	StateBacklog    State = "backlog"
+	StateInProgress State = "in_progress"
+	StateBlocked    State = "blocked"
+	StateInReview   State = "in_review"
+	StateDone       State = "done"
+	StateCancelled  State = "cancelled"
+)
+
+var states = []State{StateBacklog, StateInProgress, StateBlocked, StateInReview, StateDone, StateCancelled}
+
+// ParseState returns the state called name.
+func ParseState(name string) (State, bool) {
+	for _, state := range states {
+		if State(name) == state {
+			return state, true
+		}
+	}
+
+	return "", false
+}
+
+// StateNames lists the valid states in workflow order.
+func StateNames() []string {
+	names := make([]string, len(states))
+	for i, state := range states {
+		names[i] = string(state)
+	}
+
+	return names
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Subtask is a checklist item of a todo. Position orders the subtasks of a
+// todo and runs from 1 without gaps.
+type Subtask struct {
+	ID        uint   `gorm:"primarykey"`
+	TodoID    uint   `gorm:"not null;index"`
+	Title     string `gorm:"not null"`
+	Done      bool   `gorm:"not null;default:false"`
+	Position  int    `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Tag labels todos. Every user has tags of their own, shared between their
+// todos and deleted outright, so a name can be reused as soon as its tag is
+// gone.
+type Tag struct {
+	ID uint `gorm:"primarykey"`
+	// OwnerID is the user the tag belongs to. Tags created before there were
+	// users have 0.
+	OwnerID   uint   `gorm:"not null;default:0;uniqueIndex:idx_tags_owner_name"`
+	Name      string `gorm:"size:64;not null;uniqueIndex:idx_tags_owner_name"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
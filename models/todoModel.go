@@ -1,10 +1,43 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type ToDo struct {
 	gorm.Model
-	Title  string
-	Body   string
+	// OwnerID is the user the todo belongs to. Todos created before there
+	// were accounts have none and are not shown to anybody.
+	OwnerID uint `gorm:"not null;default:0;index"`
+	Title   string
+	Body    string
+	// Status is true exactly when State is done. It is kept for clients and
+	// filters that predate the workflow.
 	Status bool
+	State  State `gorm:"size:20;not null;default:'backlog';index" enums:"backlog,in_progress,blocked,in_review,done,cancelled"`
+	// CompletedAt is when the todo last became done, nil while it is not.
+	CompletedAt *time.Time
+	Priority    Priority `gorm:"not null;default:0;index" swaggertype:"string" enums:"none,low,medium,high,urgent"`
+	// StartAt and DueAt are optional planning dates, stored as UTC instants.
+	StartAt *time.Time `gorm:"index"`
+	DueAt   *time.Time `gorm:"index"`
+	// ProjectID is nil for todos in the inbox.
+	ProjectID *uint `gorm:"index"`
+	// Rank is the position of the todo in the manual order, a key from
+	// package ranking. It is assigned on create and changed only by moves.
+	Rank string `gorm:"not null;default:'';index"`
+	// RRule is the recurrence rule of the series the todo belongs to, empty
+	// if it does not recur. SeriesID stays set after the series ends.
+	RRule    string
+	SeriesID *uint `gorm:"index"`
+	// Tags are ordered by name and managed through the tag endpoints, not
+	// through create, replace or patch.
+	Tags []Tag `gorm:"many2many:todo_tags;joinForeignKey:TodoID;joinReferences:TagID"`
+	// CommentCount is how many comments the todo has. It is filled in when
+	// todos are read and not stored.
+	CommentCount int64 `gorm:"-"`
+	// Version increases on every update and backs the ETag of the todo.
+	Version uint `gorm:"not null;default:1"`
 }
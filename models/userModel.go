@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// User is an account that owns todos. Username and Email are stored in lower
+// case and are both unique, so either one can be used to log in. Role says
+// what the user may do.
+type User struct {
+	ID           uint   `gorm:"primarykey"`
+	Username     string `gorm:"size:50;not null;uniqueIndex"`
+	Email        string `gorm:"size:255;not null;uniqueIndex"`
+	PasswordHash string `gorm:"not null" json:"-"`
+	Role         Role   `gorm:"size:20;not null;default:'editor'" enums:"viewer,editor,admin"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Session is a login, kept alive by its refresh token. The token handed to
+// the client is never stored, only its SHA-256 hash, which changes every
+// time the token is used. A revoked session can't be refreshed any more.
+type Session struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"not null;index"`
+	TokenHash string `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,119 @@
+// Package ranking generates lexicographic sort keys for manually ordered
+// lists. A key can always be generated between any two distinct keys, so
+// moving an item only rewrites the key of that item, e.g.
+//
+//	Between("i", "j") == "ii"
+//
+// Keys are made of the digits 0-9 and lowercase letters a-z and never end in
+// '0', which is what guarantees the gap. They compare correctly as plain byte
+// strings.
+package ranking
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	middle = "i"
+)
+
+// width is how many digits After pads a key to before incrementing it, so
+// that appending to a list grows keys by one digit only every 36^3 items.
+const width = 4
+
+var ErrInvalidKey = errors.New("ranking: keys must be ordered and well formed")
+
+// After returns a key greater than a. An empty a means no lower bound, and
+// then the key sits in the middle of the key space.
+func After(a string) string {
+	if a == "" {
+		return middle
+	}
+
+	key := []byte(a)
+	for len(key) < width {
+		key = append(key, digits[0])
+	}
+
+	for i := len(key) - 1; i >= 0; i-- {
+		if d := strings.IndexByte(digits, key[i]); d < len(digits)-1 {
+			key[i] = digits[d+1]
+			return strings.TrimRight(string(key), digits[:1])
+		}
+		key[i] = digits[0]
+	}
+
+	// Every digit was the largest one.
+	return a + middle
+}
+
+// Between returns a key greater than a and less than b. An empty a means no
+// lower bound and an empty b no upper bound.
+func Between(a, b string) (string, error) {
+	if !valid(a) || !valid(b) || (b != "" && a >= b) {
+		return "", ErrInvalidKey
+	}
+	if b == "" {
+		return After(a), nil
+	}
+
+	return midpoint(a, b), nil
+}
+
+// midpoint finds a short key strictly between a and b, where a < b and an
+// empty b is unbounded.
+func midpoint(a, b string) string {
+	// Skip the common prefix; a missing digit of a counts as '0'.
+	n := 0
+	for n < len(b) && digitAt(a, n) == b[n] {
+		n++
+	}
+	if n > 0 {
+		rest := ""
+		if n < len(a) {
+			rest = a[n:]
+		}
+		return b[:n] + midpoint(rest, b[n:])
+	}
+
+	low := strings.IndexByte(digits, digitAt(a, 0))
+	high := len(digits)
+	if b != "" {
+		high = strings.IndexByte(digits, b[0])
+	}
+
+	if high-low > 1 {
+		return digits[(low+high)/2 : (low+high)/2+1]
+	}
+	// The first digits are consecutive. A longer b has a shorter prefix
+	// between a and b; otherwise extend a.
+	if len(b) > 1 {
+		return b[:1]
+	}
+
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return digits[low:low+1] + midpoint(rest, "")
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+
+	return digits[0]
+}
+
+func valid(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+
+	return !strings.HasSuffix(key, digits[:1])
+}
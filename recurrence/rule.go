@@ -0,0 +1,435 @@
+// Package recurrence implements the subset of iCalendar recurrence rules
+// (RFC 5545, section 3.3.10) that makes sense for todos, e.g.
+//
+//	FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH
+//	FREQ=MONTHLY;BYDAY=-1FR;COUNT=12
+//
+// FREQ may be DAILY, WEEKLY, MONTHLY or YEARLY, refined by INTERVAL, COUNT
+// or UNTIL, BYDAY, BYMONTHDAY and BYMONTH. Weeks start on Monday. Rules with
+// other parts are rejected rather than silently misread.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("Invalid recurrence rule")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the search for occurrences, so a rule that can never
+// match, such as the 30th of February, gives up instead of looping forever.
+const maxPeriods = 10000
+
+// WeekdayNum is a BYDAY entry: a weekday, optionally the Nth one of the month
+// (negative N counts from the end).
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+var weekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse reads a rule such as "FREQ=DAILY;COUNT=5", with or without the
+// "RRULE:" prefix.
+func Parse(text string) (*Rule, error) {
+	text = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(text)), "RRULE:")
+	rule := &Rule{Interval: 1}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(text, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" || seen[name] {
+			return nil, invalid("malformed part %q", part)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly && rule.Freq != Yearly {
+				err = invalid("unsupported FREQ %s", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(name, value)
+		case "COUNT":
+			rule.Count, err = positive(name, value)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseList(name, value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseList(name, value, 1, 12)
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			if value != "MO" {
+				err = invalid("only WKST=MO is supported")
+			}
+		default:
+			err = invalid("unsupported part %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rule.check(); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *Rule) check() error {
+	switch {
+	case r.Freq == "":
+		return invalid("FREQ is required")
+	case r.Count > 0 && r.Until != nil:
+		return invalid("COUNT and UNTIL can't be used together")
+	case r.Freq == Weekly && len(r.ByMonthDay) > 0:
+		return invalid("BYMONTHDAY can't be used with FREQ=WEEKLY")
+	case r.Freq == Yearly && len(r.ByMonth) == 0 && (len(r.ByDay) > 0 || len(r.ByMonthDay) > 0):
+		return invalid("FREQ=YEARLY needs BYMONTH to use BYDAY or BYMONTHDAY")
+	}
+
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, day := range r.ByDay {
+			if day.N != 0 {
+				return invalid("numbered BYDAY needs FREQ=MONTHLY or FREQ=YEARLY")
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns the rule in canonical form, without the "RRULE:" prefix.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = weekdays[day.Weekday]
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after the given time of the series
+// starting at start. start is always the first occurrence, whether or not it
+// matches the rule, and the occurrences keep its time of day and location.
+// ok is false once the series is over.
+func (r *Rule) Next(start time.Time, after time.Time) (next time.Time, ok bool) {
+	found := false
+	r.each(start, func(occurrence time.Time) bool {
+		if occurrence.After(after) {
+			next, found = occurrence, true
+			return false
+		}
+		return true
+	})
+
+	return next, found
+}
+
+// Before counts the occurrences of the series starting at start that come
+// before t.
+func (r *Rule) Before(start time.Time, t time.Time) int {
+	n := 0
+	r.each(start, func(occurrence time.Time) bool {
+		if !occurrence.Before(t) {
+			return false
+		}
+		n++
+		return true
+	})
+
+	return n
+}
+
+// each calls yield with the occurrences in order until it returns false or
+// the series ends.
+func (r *Rule) each(start time.Time, yield func(time.Time) bool) {
+	n := 0
+	emit := func(occurrence time.Time) bool {
+		n++
+		if r.Count > 0 && n > r.Count {
+			return false
+		}
+		if r.Until != nil && occurrence.After(*r.Until) {
+			return false
+		}
+		return yield(occurrence)
+	}
+
+	if !emit(start) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, occurrence := range r.expand(start, period) {
+			if !occurrence.After(start) {
+				continue
+			}
+			if !emit(occurrence) {
+				return
+			}
+		}
+	}
+}
+
+// expand returns the candidate occurrences in the given period after the
+// one containing start, in order.
+func (r *Rule) expand(start time.Time, period int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	step := period * r.Interval
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := at(start.Year(), start.Month(), start.Day()+step)
+		if r.matchesDay(day) {
+			days = append(days, day)
+		}
+	case Weekly:
+		monday := start.Day() - (int(start.Weekday())+6)%7
+		for i := 0; i < 7; i++ {
+			day := at(start.Year(), start.Month(), monday+7*step+i)
+			if r.inWeek(day, start) && r.inMonths(day) {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		first := at(start.Year(), start.Month()+time.Month(step), 1)
+		if r.inMonths(first) {
+			days = r.inMonth(first, start)
+		}
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			days = append(days, r.inMonth(at(start.Year()+step, month, 1), start)...)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// inMonth lists the days of the month starting at first that the rule
+// selects, defaulting to the day of month of start.
+func (r *Rule) inMonth(first time.Time, start time.Time) []time.Time {
+	length := first.AddDate(0, 1, -1).Day()
+
+	var days []time.Time
+	for day := 1; day <= length; day++ {
+		date := first.AddDate(0, 0, day-1)
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if day == start.Day() {
+				days = append(days, date)
+			}
+			continue
+		}
+		if (len(r.ByMonthDay) == 0 || r.isMonthDay(day, length)) &&
+			(len(r.ByDay) == 0 || r.isNthWeekday(date, day, length)) {
+			days = append(days, date)
+		}
+	}
+
+	return days
+}
+
+func (r *Rule) matchesDay(day time.Time) bool {
+	length := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	return r.inMonths(day) &&
+		(len(r.ByMonthDay) == 0 || r.isMonthDay(day.Day(), length)) &&
+		(len(r.ByDay) == 0 || r.isNthWeekday(day, day.Day(), length))
+}
+
+// inWeek reports whether a day of a weekly period is selected: one of the
+// BYDAY weekdays, or the weekday of start if there are none.
+func (r *Rule) inWeek(day time.Time, start time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return day.Weekday() == start.Weekday()
+	}
+
+	for _, weekday := range r.ByDay {
+		if weekday.Weekday == day.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) inMonths(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+
+	for _, month := range r.ByMonth {
+		if month == day.Month() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) isMonthDay(day int, length int) bool {
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day || monthDay == day-length-1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) isNthWeekday(date time.Time, day int, length int) bool {
+	for _, weekday := range r.ByDay {
+		if weekday.Weekday != date.Weekday() {
+			continue
+		}
+		if weekday.N == 0 ||
+			(weekday.N > 0 && (day-1)/7+1 == weekday.N) ||
+			(weekday.N < 0 && (length-day)/7+1 == -weekday.N) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, invalid("malformed BYDAY %q", item)
+		}
+
+		weekday := -1
+		for i, name := range weekdays {
+			if strings.HasSuffix(item, name) {
+				weekday = i
+			}
+		}
+		if weekday < 0 {
+			return nil, invalid("malformed BYDAY %q", item)
+		}
+
+		day := WeekdayNum{Weekday: time.Weekday(weekday)}
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, invalid("malformed BYDAY %q", item)
+			}
+			day.N = n
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func parseList(name string, value string, low int, high int) ([]int, error) {
+	var list []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < low || n > high {
+			return nil, invalid("malformed %s %q", name, item)
+		}
+		list = append(list, n)
+	}
+
+	return list, nil
+}
+
+func parseUntil(value string) (*time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return &t, nil
+	}
+
+	// A date includes the whole day.
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return nil, invalid("malformed UNTIL %q", value)
+	}
+	t = t.Add(24*time.Hour - time.Second)
+
+	return &t, nil
+}
+
+func positive(name string, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, invalid("%s have to be a positive number", name)
+	}
+
+	return n, nil
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidRule}, args...)...)
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"example/Studying/models"
+
+	"gorm.io/gorm"
+)
+
+// AttachmentRepository stores attachment metadata. Files can only be
+// attached to live todos; Create fails with ErrNotFound otherwise. Purging a
+// todo leaves its attachments behind as orphans, so that their content can
+// be removed from the blob store before the metadata goes.
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	// List returns the attachments of a todo, oldest first.
+	List(todoID uint) ([]models.Attachment, error)
+	FindByID(todoID uint, id uint) (*models.Attachment, error)
+	Delete(todoID uint, id uint) error
+	// Orphans returns the attachments of todos that no longer exist.
+	Orphans() ([]models.Attachment, error)
+}
+
+type GormAttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAttachmentRepository(db *gorm.DB) *GormAttachmentRepository {
+	return &GormAttachmentRepository{db: db}
+}
+
+func (r *GormAttachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.ToDo{}, attachment.TodoID).Error; err != nil {
+			return notFound(err)
+		}
+
+		return tx.Create(attachment).Error
+	})
+}
+
+func (r *GormAttachmentRepository) List(todoID uint) ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	if err := r.db.Where("todo_id = ?", todoID).Order("id").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *GormAttachmentRepository) FindByID(todoID uint, id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+
+	if err := r.db.Where("todo_id = ?", todoID).First(&attachment, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &attachment, nil
+}
+
+func (r *GormAttachmentRepository) Delete(todoID uint, id uint) error {
+	result := r.db.Where("todo_id = ?", todoID).Delete(&models.Attachment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *GormAttachmentRepository) Orphans() ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	todos := r.db.Unscoped().Model(&models.ToDo{}).Select("id")
+	if err := r.db.Where("todo_id NOT IN (?)", todos).Order("id").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"example/Studying/models"
+
+	"gorm.io/gorm"
+)
+
+// CommentRepository stores the discussion threads of todos. Comments can only
+// be added to live todos; Create fails with ErrNotFound otherwise. Every
+// change bumps the version of the todo, whose CommentCount is part of it.
+type CommentRepository interface {
+	Create(comment *models.Comment) error
+	// List returns a window of the comments of a todo, oldest first. Only
+	// Limit and Offset of page apply.
+	List(todoID uint, page Page) ([]models.Comment, error)
+	Count(todoID uint) (int64, error)
+	FindByID(todoID uint, id uint) (*models.Comment, error)
+	// Update saves the body of comment.
+	Update(comment *models.Comment) error
+	Delete(todoID uint, id uint) error
+}
+
+type GormCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormCommentRepository(db *gorm.DB) *GormCommentRepository {
+	return &GormCommentRepository{db: db}
+}
+
+func (r *GormCommentRepository) Create(comment *models.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.ToDo{}, comment.TodoID).Error; err != nil {
+			return notFound(err)
+		}
+
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+
+		return bumpVersion(tx.Where("id = ?", comment.TodoID))
+	})
+}
+
+func (r *GormCommentRepository) List(todoID uint, page Page) ([]models.Comment, error) {
+	comments := []models.Comment{}
+
+	tx := paginate(r.db.Where("todo_id = ?", todoID).Order("id"), page)
+	if err := tx.Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (r *GormCommentRepository) Count(todoID uint) (int64, error) {
+	var total int64
+
+	if err := r.db.Model(&models.Comment{}).Where("todo_id = ?", todoID).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *GormCommentRepository) FindByID(todoID uint, id uint) (*models.Comment, error) {
+	var comment models.Comment
+
+	if err := r.db.Where("todo_id = ?", todoID).First(&comment, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &comment, nil
+}
+
+func (r *GormCommentRepository) Update(comment *models.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(comment).Where("todo_id = ?", comment.TodoID).Update("body", comment.Body)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return bumpVersion(tx.Where("id = ?", comment.TodoID))
+	})
+}
+
+func (r *GormCommentRepository) Delete(todoID uint, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("todo_id = ?", todoID).Delete(&models.Comment{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return bumpVersion(tx.Where("id = ?", todoID))
+	})
+}
+
+// loadCommentCounts fills in the comment counts of todos with a single query.
+func loadCommentCounts(db *gorm.DB, todos []models.ToDo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	var rows []struct {
+		TodoID uint
+		Count  int64
+	}
+	err := db.Model(&models.Comment{}).
+		Select("todo_id, COUNT(*) AS count").
+		Where("todo_id IN ?", ids).
+		Group("todo_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	counts := map[uint]int64{}
+	for _, row := range rows {
+		counts[row.TodoID] = row.Count
+	}
+	for i := range todos {
+		todos[i].CommentCount = counts[todos[i].ID]
+	}
+
+	return nil
+}
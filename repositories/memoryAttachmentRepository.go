@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"sort"
+	"time"
+)
+
+// MemoryAttachmentRepository serves the attachments kept by a
+// MemoryTodoRepository.
+type MemoryAttachmentRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemoryAttachmentRepository(todos *MemoryTodoRepository) *MemoryAttachmentRepository {
+	return &MemoryAttachmentRepository{r: todos}
+}
+
+func (s *MemoryAttachmentRepository) Create(attachment *models.Attachment) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if todo, ok := s.r.todos[attachment.TodoID]; !ok || todo.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	attachment.ID = s.r.nextAttachmentID
+	attachment.CreatedAt = time.Now()
+	s.r.nextAttachmentID++
+	s.r.attachments[attachment.ID] = *attachment
+
+	return nil
+}
+
+func (s *MemoryAttachmentRepository) List(todoID uint) ([]models.Attachment, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	return s.filter(func(attachment models.Attachment) bool { return attachment.TodoID == todoID }), nil
+}
+
+func (s *MemoryAttachmentRepository) FindByID(todoID uint, id uint) (*models.Attachment, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	attachment, ok := s.r.attachments[id]
+	if !ok || attachment.TodoID != todoID {
+		return nil, ErrNotFound
+	}
+
+	return &attachment, nil
+}
+
+func (s *MemoryAttachmentRepository) Delete(todoID uint, id uint) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if attachment, ok := s.r.attachments[id]; !ok || attachment.TodoID != todoID {
+		return ErrNotFound
+	}
+	delete(s.r.attachments, id)
+
+	return nil
+}
+
+func (s *MemoryAttachmentRepository) Orphans() ([]models.Attachment, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	return s.filter(func(attachment models.Attachment) bool {
+		_, ok := s.r.todos[attachment.TodoID]
+		return !ok
+	}), nil
+}
+
+// filter returns the attachments matching keep ordered by id. Callers must
+// hold s.r.mu.
+func (s *MemoryAttachmentRepository) filter(keep func(models.Attachment) bool) []models.Attachment {
+	attachments := []models.Attachment{}
+	for _, attachment := range s.r.attachments {
+		if keep(attachment) {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+
+	return attachments
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"sort"
+	"time"
+)
+
+// MemoryCommentRepository serves the comments kept by a MemoryTodoRepository.
+type MemoryCommentRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemoryCommentRepository(todos *MemoryTodoRepository) *MemoryCommentRepository {
+	return &MemoryCommentRepository{r: todos}
+}
+
+func (s *MemoryCommentRepository) Create(comment *models.Comment) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if todo, ok := s.r.todos[comment.TodoID]; !ok || todo.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	comment.ID = s.r.nextCommentID
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	s.r.nextCommentID++
+	s.r.comments[comment.ID] = *comment
+	s.r.bump(comment.TodoID)
+
+	return nil
+}
+
+func (s *MemoryCommentRepository) List(todoID uint, page Page) ([]models.Comment, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	comments := s.of(todoID)
+	if page.Offset > 0 {
+		comments = comments[min(page.Offset, len(comments)):]
+	}
+	if page.Limit > 0 {
+		comments = comments[:min(page.Limit, len(comments))]
+	}
+
+	return comments, nil
+}
+
+func (s *MemoryCommentRepository) Count(todoID uint) (int64, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	return int64(len(s.of(todoID))), nil
+}
+
+func (s *MemoryCommentRepository) FindByID(todoID uint, id uint) (*models.Comment, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	comment, ok := s.r.comments[id]
+	if !ok || comment.TodoID != todoID {
+		return nil, ErrNotFound
+	}
+
+	return &comment, nil
+}
+
+func (s *MemoryCommentRepository) Update(comment *models.Comment) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	stored, ok := s.r.comments[comment.ID]
+	if !ok || stored.TodoID != comment.TodoID {
+		return ErrNotFound
+	}
+
+	stored.Body = comment.Body
+	stored.UpdatedAt = time.Now()
+	s.r.comments[comment.ID] = stored
+	*comment = stored
+	s.r.bump(comment.TodoID)
+
+	return nil
+}
+
+func (s *MemoryCommentRepository) Delete(todoID uint, id uint) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if comment, ok := s.r.comments[id]; !ok || comment.TodoID != todoID {
+		return ErrNotFound
+	}
+	delete(s.r.comments, id)
+	s.r.bump(todoID)
+
+	return nil
+}
+
+// of returns the comments of a todo, oldest first. Callers must hold s.r.mu.
+func (s *MemoryCommentRepository) of(todoID uint) []models.Comment {
+	comments := []models.Comment{}
+	for _, comment := range s.r.comments {
+		if comment.TodoID == todoID {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+
+	return comments
+}
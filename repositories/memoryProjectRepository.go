@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"slices"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryProjectRepository serves the projects kept by a MemoryTodoRepository.
+type MemoryProjectRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemoryProjectRepository(todos *MemoryTodoRepository) *MemoryProjectRepository {
+	return &MemoryProjectRepository{r: todos}
+}
+
+func (p *MemoryProjectRepository) Create(project *models.Project) error {
+	p.r.mu.Lock()
+	defer p.r.mu.Unlock()
+
+	now := time.Now()
+	project.ID = p.r.nextProjectID
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	p.r.nextProjectID++
+
+	p.r.projects[project.ID] = *project
+
+	return nil
+}
+
+func (p *MemoryProjectRepository) List(query ProjectQuery) ([]models.Project, error) {
+	p.r.mu.RLock()
+	defer p.r.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, project := range p.r.projects {
+		if query.OwnerID != nil && project.OwnerID != *query.OwnerID && !slices.Contains(query.Shared, project.ID) {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
+
+func (p *MemoryProjectRepository) FindByID(id uint) (*models.Project, error) {
+	p.r.mu.RLock()
+	defer p.r.mu.RUnlock()
+
+	project, ok := p.r.projects[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &project, nil
+}
+
+func (p *MemoryProjectRepository) Update(project *models.Project) error {
+	p.r.mu.Lock()
+	defer p.r.mu.Unlock()
+
+	stored, ok := p.r.projects[project.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	stored.Name = project.Name
+	stored.Description = project.Description
+	stored.UpdatedAt = time.Now()
+	p.r.projects[project.ID] = stored
+	*project = stored
+
+	return nil
+}
+
+func (p *MemoryProjectRepository) Delete(id uint, trash bool) error {
+	p.r.mu.Lock()
+	defer p.r.mu.Unlock()
+
+	project, ok := p.r.projects[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	for todoID, todo := range p.r.todos {
+		if todo.ProjectID == nil || *todo.ProjectID != id {
+			continue
+		}
+
+		todo.ProjectID = nil
+		if !todo.DeletedAt.Valid {
+			todo.Version++
+			todo.UpdatedAt = now
+			if trash && todo.OwnerID == project.OwnerID {
+				todo.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			}
+		}
+		p.r.todos[todoID] = todo
+	}
+	for seriesID, series := range p.r.series {
+		if series.ProjectID != nil && *series.ProjectID == id {
+			series.ProjectID = nil
+			p.r.series[seriesID] = series
+		}
+	}
+	for shareID, share := range p.r.shares {
+		if share.ProjectID != nil && *share.ProjectID == id {
+			delete(p.r.shares, shareID)
+		}
+	}
+	delete(p.r.projects, id)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"time"
+)
+
+// MemorySeriesRepository serves the series kept by a MemoryTodoRepository.
+type MemorySeriesRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemorySeriesRepository(todos *MemoryTodoRepository) *MemorySeriesRepository {
+	return &MemorySeriesRepository{r: todos}
+}
+
+func (s *MemorySeriesRepository) Create(series *models.Series) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	now := time.Now()
+	series.ID = s.r.nextSeriesID
+	series.CreatedAt = now
+	series.UpdatedAt = now
+	s.r.nextSeriesID++
+
+	s.r.series[series.ID] = *series
+
+	return nil
+}
+
+func (s *MemorySeriesRepository) FindByID(id uint) (*models.Series, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	series, ok := s.r.series[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &series, nil
+}
+
+func (s *MemorySeriesRepository) Update(series *models.Series) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	stored, ok := s.r.series[series.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	series.CreatedAt = stored.CreatedAt
+	series.UpdatedAt = time.Now()
+	s.r.series[series.ID] = *series
+
+	return nil
+}
+
+func (s *MemorySeriesRepository) Advance(series *models.Series, next *models.ToDo) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	stored, ok := s.r.series[series.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.LatestID != series.LatestID {
+		return ErrVersionConflict
+	}
+
+	if err := s.r.create(next); err != nil {
+		return err
+	}
+
+	stored.LatestID = next.ID
+	stored.UpdatedAt = time.Now()
+	s.r.series[series.ID] = stored
+	series.LatestID = next.ID
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"sort"
+	"time"
+)
+
+// MemoryShareRepository serves the shares kept by a MemoryTodoRepository, so
+// that purging a todo also drops its shares.
+type MemoryShareRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemoryShareRepository(todos *MemoryTodoRepository) *MemoryShareRepository {
+	return &MemoryShareRepository{r: todos}
+}
+
+func (r *MemoryShareRepository) Create(share *models.Share) error {
+	r.r.mu.Lock()
+	defer r.r.mu.Unlock()
+
+	for _, existing := range r.r.shares {
+		if existing.UserID != share.UserID {
+			continue
+		}
+		if share.TodoID != nil && existing.TodoID != nil && *existing.TodoID == *share.TodoID {
+			return ErrDuplicateShare
+		}
+		if share.ProjectID != nil && existing.ProjectID != nil && *existing.ProjectID == *share.ProjectID && existing.OwnerID == share.OwnerID {
+			return ErrDuplicateShare
+		}
+	}
+
+	now := time.Now()
+	share.ID = r.r.nextShareID
+	share.CreatedAt = now
+	share.UpdatedAt = now
+	r.r.nextShareID++
+
+	stored := *share
+	stored.User = nil
+	r.r.shares[share.ID] = stored
+
+	return nil
+}
+
+func (r *MemoryShareRepository) FindByID(id uint) (*models.Share, error) {
+	r.r.mu.RLock()
+	defer r.r.mu.RUnlock()
+
+	share, ok := r.r.shares[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &share, nil
+}
+
+func (r *MemoryShareRepository) ListForTodo(todoID uint) ([]models.Share, error) {
+	return r.list(func(share models.Share) bool {
+		return share.TodoID != nil && *share.TodoID == todoID
+	}), nil
+}
+
+func (r *MemoryShareRepository) ListForProject(ownerID uint, projectID uint) ([]models.Share, error) {
+	return r.list(func(share models.Share) bool {
+		return share.OwnerID == ownerID && share.ProjectID != nil && *share.ProjectID == projectID
+	}), nil
+}
+
+func (r *MemoryShareRepository) ListForUser(userID uint) ([]models.Share, error) {
+	return r.list(func(share models.Share) bool { return share.UserID == userID }), nil
+}
+
+func (r *MemoryShareRepository) Delete(id uint) error {
+	r.r.mu.Lock()
+	defer r.r.mu.Unlock()
+
+	if _, ok := r.r.shares[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.r.shares, id)
+
+	return nil
+}
+
+func (r *MemoryShareRepository) list(keep func(models.Share) bool) []models.Share {
+	r.r.mu.RLock()
+	defer r.r.mu.RUnlock()
+
+	shares := []models.Share{}
+	for _, share := range r.r.shares {
+		if keep(share) {
+			shares = append(shares, share)
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].ID < shares[j].ID })
+
+	return shares
+}
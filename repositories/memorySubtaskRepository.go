@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MemorySubtaskRepository serves the subtasks kept by a MemoryTodoRepository.
+type MemorySubtaskRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemorySubtaskRepository(todos *MemoryTodoRepository) *MemorySubtaskRepository {
+	return &MemorySubtaskRepository{r: todos}
+}
+
+func (s *MemorySubtaskRepository) Create(subtask *models.Subtask) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	order, err := s.order(subtask.TodoID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	subtask.ID = s.r.nextSubtaskID
+	subtask.CreatedAt = now
+	subtask.UpdatedAt = now
+	s.r.nextSubtaskID++
+
+	position := subtask.Position
+	if position <= 0 {
+		position = len(order) + 1
+	}
+	s.r.subtasks[subtask.ID] = *subtask
+	s.renumber(placeAt(append(order, subtask.ID), subtask.ID, position))
+	*subtask = s.r.subtasks[subtask.ID]
+	s.r.bump(subtask.TodoID)
+
+	return nil
+}
+
+func (s *MemorySubtaskRepository) List(todoID uint) ([]models.Subtask, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	subtasks := []models.Subtask{}
+	for _, subtask := range s.r.subtasks {
+		if subtask.TodoID == todoID {
+			subtasks = append(subtasks, subtask)
+		}
+	}
+	sortSubtasks(subtasks)
+
+	return subtasks, nil
+}
+
+func (s *MemorySubtaskRepository) FindByID(todoID uint, id uint) (*models.Subtask, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	subtask, ok := s.r.subtasks[id]
+	if !ok || subtask.TodoID != todoID {
+		return nil, ErrNotFound
+	}
+
+	return &subtask, nil
+}
+
+func (s *MemorySubtaskRepository) Update(subtask *models.Subtask) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	order, err := s.order(subtask.TodoID)
+	if err != nil {
+		return err
+	}
+	stored, ok := s.r.subtasks[subtask.ID]
+	if !ok || stored.TodoID != subtask.TodoID {
+		return ErrNotFound
+	}
+
+	stored.Title = subtask.Title
+	stored.Done = subtask.Done
+	stored.UpdatedAt = time.Now()
+	s.r.subtasks[subtask.ID] = stored
+	s.renumber(placeAt(order, subtask.ID, subtask.Position))
+	*subtask = s.r.subtasks[subtask.ID]
+	s.r.bump(subtask.TodoID)
+
+	return nil
+}
+
+func (s *MemorySubtaskRepository) Delete(todoID uint, id uint) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	order, err := s.order(todoID)
+	if err != nil {
+		return err
+	}
+	if subtask, ok := s.r.subtasks[id]; !ok || subtask.TodoID != todoID {
+		return ErrNotFound
+	}
+
+	delete(s.r.subtasks, id)
+	s.renumber(slices.DeleteFunc(order, func(other uint) bool { return other == id }))
+	s.r.bump(todoID)
+
+	return nil
+}
+
+// order returns the subtask ids of a live todo in order. Callers must hold
+// s.r.mu.
+func (s *MemorySubtaskRepository) order(todoID uint) ([]uint, error) {
+	todo, ok := s.r.todos[todoID]
+	if !ok || todo.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+
+	subtasks := []models.Subtask{}
+	for _, subtask := range s.r.subtasks {
+		if subtask.TodoID == todoID {
+			subtasks = append(subtasks, subtask)
+		}
+	}
+	sortSubtasks(subtasks)
+
+	ids := make([]uint, len(subtasks))
+	for i, subtask := range subtasks {
+		ids[i] = subtask.ID
+	}
+
+	return ids, nil
+}
+
+// renumber stores the positions implied by order. Callers must hold s.r.mu.
+func (s *MemorySubtaskRepository) renumber(order []uint) {
+	for i, id := range order {
+		subtask := s.r.subtasks[id]
+		subtask.Position = i + 1
+		s.r.subtasks[id] = subtask
+	}
+}
+
+func sortSubtasks(subtasks []models.Subtask) {
+	sort.Slice(subtasks, func(i, j int) bool {
+		if subtasks[i].Position != subtasks[j].Position {
+			return subtasks[i].Position < subtasks[j].Position
+		}
+		return subtasks[i].ID < subtasks[j].ID
+	})
+}
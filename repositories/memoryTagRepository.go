@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"sort"
+	"time"
+)
+
+// MemoryTagRepository serves the tags kept by a MemoryTodoRepository.
+type MemoryTagRepository struct {
+	r *MemoryTodoRepository
+}
+
+func NewMemoryTagRepository(todos *MemoryTodoRepository) *MemoryTagRepository {
+	return &MemoryTagRepository{r: todos}
+}
+
+func (t *MemoryTagRepository) Create(tag *models.Tag) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+
+	if t.nameTaken(tag.OwnerID, tag.Name, 0) {
+		return ErrDuplicateTag
+	}
+
+	now := time.Now()
+	tag.ID = t.r.nextTagID
+	tag.CreatedAt = now
+	tag.UpdatedAt = now
+	t.r.nextTagID++
+
+	t.r.tags[tag.ID] = *tag
+
+	return nil
+}
+
+func (t *MemoryTagRepository) List(ownerID uint) ([]models.Tag, error) {
+	t.r.mu.RLock()
+	defer t.r.mu.RUnlock()
+
+	tags := []models.Tag{}
+	for _, tag := range t.r.tags {
+		if tag.OwnerID == ownerID {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+func (t *MemoryTagRepository) FindByID(id uint) (*models.Tag, error) {
+	t.r.mu.RLock()
+	defer t.r.mu.RUnlock()
+
+	tag, ok := t.r.tags[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &tag, nil
+}
+
+func (t *MemoryTagRepository) Update(tag *models.Tag) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+
+	stored, ok := t.r.tags[tag.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if t.nameTaken(stored.OwnerID, tag.Name, tag.ID) {
+		return ErrDuplicateTag
+	}
+
+	stored.Name = tag.Name
+	stored.UpdatedAt = time.Now()
+	t.r.tags[tag.ID] = stored
+	*tag = stored
+	t.bumpTagged(tag.ID)
+
+	return nil
+}
+
+func (t *MemoryTagRepository) Delete(id uint) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+
+	if _, ok := t.r.tags[id]; !ok {
+		return ErrNotFound
+	}
+
+	t.bumpTagged(id)
+	for _, labels := range t.r.labels {
+		delete(labels, id)
+	}
+	delete(t.r.tags, id)
+
+	return nil
+}
+
+func (t *MemoryTagRepository) Attach(todoID uint, tagID uint) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+
+	todo, ok := t.r.todos[todoID]
+	if !ok || todo.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if _, ok := t.r.tags[tagID]; !ok {
+		return ErrNotFound
+	}
+	if t.r.labels[todoID][tagID] {
+		return nil
+	}
+
+	if t.r.labels[todoID] == nil {
+		t.r.labels[todoID] = map[uint]bool{}
+	}
+	t.r.labels[todoID][tagID] = true
+	t.r.bump(todoID)
+
+	return nil
+}
+
+func (t *MemoryTagRepository) Detach(todoID uint, tagID uint) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+
+	todo, ok := t.r.todos[todoID]
+	if !ok || todo.DeletedAt.Valid || !t.r.labels[todoID][tagID] {
+		return ErrNotFound
+	}
+
+	delete(t.r.labels[todoID], tagID)
+	t.r.bump(todoID)
+
+	return nil
+}
+
+// nameTaken reports whether a tag of ownerID other than id is called name.
+// Callers must hold t.r.mu.
+func (t *MemoryTagRepository) nameTaken(ownerID uint, name string, id uint) bool {
+	for _, tag := range t.r.tags {
+		if tag.OwnerID == ownerID && tag.Name == name && tag.ID != id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bumpTagged bumps the version of every live todo labelled with the tag.
+// Callers must hold t.r.mu.
+func (t *MemoryTagRepository) bumpTagged(tagID uint) {
+	for todoID, labels := range t.r.labels {
+		if labels[tagID] {
+			t.r.bump(todoID)
+		}
+	}
+}
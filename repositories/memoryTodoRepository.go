@@ -0,0 +1,467 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"example/Studying/ranking"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryTodoRepository keeps todos in a map guarded by a mutex. It mirrors the
+// gorm.Model semantics of the Postgres backend: ids auto-increment, timestamps
+// are maintained on create/update and deletes are soft. It also holds the
+// tags, subtasks, comments, attachments, projects, series and shares served
+// by MemoryTagRepository, MemorySubtaskRepository, MemoryCommentRepository,
+// MemoryAttachmentRepository, MemoryProjectRepository, MemorySeriesRepository
+// and MemoryShareRepository, so that they all share one lock.
+type MemoryTodoRepository struct {
+	mu     sync.RWMutex
+	nextID uint
+	todos  map[uint]models.ToDo
+
+	nextTagID uint
+	tags      map[uint]models.Tag
+	// labels maps a todo id to the ids of its tags.
+	labels map[uint]map[uint]bool
+
+	nextSubtaskID uint
+	subtasks      map[uint]models.Subtask
+
+	nextCommentID uint
+	comments      map[uint]models.Comment
+
+	nextAttachmentID uint
+	attachments      map[uint]models.Attachment
+
+	nextProjectID uint
+	projects      map[uint]models.Project
+
+	nextSeriesID uint
+	series       map[uint]models.Series
+
+	nextShareID uint
+	shares      map[uint]models.Share
+}
+
+func NewMemoryTodoRepository() *MemoryTodoRepository {
+	return &MemoryTodoRepository{
+		nextID:    1,
+		todos:     make(map[uint]models.ToDo),
+		nextTagID: 1,
+		tags:      make(map[uint]models.Tag),
+		labels:    make(map[uint]map[uint]bool),
+
+		nextSubtaskID: 1,
+		subtasks:      make(map[uint]models.Subtask),
+
+		nextCommentID: 1,
+		comments:      make(map[uint]models.Comment),
+
+		nextAttachmentID: 1,
+		attachments:      make(map[uint]models.Attachment),
+
+		nextProjectID: 1,
+		projects:      make(map[uint]models.Project),
+
+		nextSeriesID: 1,
+		series:       make(map[uint]models.Series),
+
+		nextShareID: 1,
+		shares:      make(map[uint]models.Share),
+	}
+}
+
+func (r *MemoryTodoRepository) Create(todo *models.ToDo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(todo)
+}
+
+// create implements Create. Callers must hold r.mu.
+func (r *MemoryTodoRepository) create(todo *models.ToDo) error {
+	if !r.projectExists(todo.ProjectID) {
+		return ErrProjectNotFound
+	}
+
+	if todo.Rank == "" {
+		todo.Rank = ranking.After(r.adjacentRank("", false, 0))
+	}
+
+	defaultState(todo)
+	now := time.Now()
+	todo.ID = r.nextID
+	todo.Version = 1
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+	r.nextID++
+
+	r.todos[todo.ID] = *todo
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) List(query TodoQuery) ([]models.ToDo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fields, keys, err := orderKeys(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	var after, before []interface{}
+	page := query.Page
+	if page.After != nil {
+		if after, err = cursorValues(page.After, keys); err != nil {
+			return nil, err
+		}
+	}
+	if page.Before != nil {
+		if before, err = cursorValues(page.Before, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	todos := r.filter(func(todo models.ToDo) bool {
+		return matches(todo, query) &&
+			(after == nil || compareToValues(todo, after, fields, keys) > 0) &&
+			(before == nil || compareToValues(todo, before, fields, keys) < 0)
+	})
+
+	sort.SliceStable(todos, func(i, j int) bool {
+		return compareToValues(todos[i], valuesOf(todos[j], keys), fields, keys) < 0
+	})
+
+	if before != nil {
+		reverse(todos)
+	}
+	if page.Offset > 0 {
+		todos = todos[min(page.Offset, len(todos)):]
+	}
+	if page.Limit > 0 {
+		todos = todos[:min(page.Limit, len(todos))]
+	}
+	if before != nil {
+		reverse(todos)
+	}
+
+	return todos, nil
+}
+
+func (r *MemoryTodoRepository) Count(query TodoQuery) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := r.filter(func(todo models.ToDo) bool { return matches(todo, query) })
+
+	return int64(len(todos)), nil
+}
+
+func (r *MemoryTodoRepository) Search(text string, query TodoQuery) ([]SearchHit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hits := []SearchHit{}
+	for _, todo := range r.filter(func(todo models.ToDo) bool { return matches(todo, query) }) {
+		if hit, ok := substringHit(todo, text); ok {
+			hits = append(hits, hit)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Rank > hits[j].Rank })
+
+	page := query.Page
+	if page.Offset > 0 {
+		hits = hits[min(page.Offset, len(hits)):]
+	}
+	if page.Limit > 0 {
+		hits = hits[:min(page.Limit, len(hits))]
+	}
+
+	return hits, nil
+}
+
+func (r *MemoryTodoRepository) FindByID(id uint) (*models.ToDo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.todos[id]
+	if !ok || todo.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	todo.Tags = r.tagsOf(id)
+	todo.CommentCount = r.commentCounts()[id]
+
+	return &todo, nil
+}
+
+func (r *MemoryTodoRepository) Update(todo *models.ToDo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.todos[todo.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if stored.Version != todo.Version {
+		return ErrVersionConflict
+	}
+	if !r.projectExists(todo.ProjectID) {
+		return ErrProjectNotFound
+	}
+
+	todo.Version++
+	todo.OwnerID = stored.OwnerID
+	todo.CreatedAt = stored.CreatedAt
+	todo.UpdatedAt = time.Now()
+	r.todos[todo.ID] = *todo
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) Delete(id uint, version uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, ok := r.todos[id]
+	if !ok || todo.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if version > 0 && todo.Version != version {
+		return ErrVersionConflict
+	}
+
+	todo.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.todos[id] = todo
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) FindByIDUnscoped(id uint) (*models.ToDo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.todos[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	todo.Tags = r.tagsOf(id)
+	todo.CommentCount = r.commentCounts()[id]
+
+	return &todo, nil
+}
+
+func (r *MemoryTodoRepository) Restore(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, ok := r.todos[id]
+	if !ok || !todo.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	todo.DeletedAt = gorm.DeletedAt{}
+	todo.Version++
+	todo.UpdatedAt = time.Now()
+	r.todos[id] = todo
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) Purge(id uint, version uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, ok := r.todos[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if version > 0 && todo.Version != version {
+		return ErrVersionConflict
+	}
+
+	r.forget(id)
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, todo := range r.todos {
+		if todo.DeletedAt.Valid && todo.DeletedAt.Time.Before(cutoff) {
+			r.forget(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *MemoryTodoRepository) AdjacentRank(rank string, next bool, exclude uint) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.adjacentRank(rank, next, exclude), nil
+}
+
+// adjacentRank implements AdjacentRank. Callers must hold r.mu.
+func (r *MemoryTodoRepository) adjacentRank(rank string, next bool, exclude uint) string {
+	adjacent := ""
+	for id, todo := range r.todos {
+		if id == exclude {
+			continue
+		}
+		if next && todo.Rank > rank && (adjacent == "" || todo.Rank < adjacent) {
+			adjacent = todo.Rank
+		}
+		if !next && (rank == "" || todo.Rank < rank) && todo.Rank > adjacent {
+			adjacent = todo.Rank
+		}
+	}
+
+	return adjacent
+}
+
+// bump bumps the version of a live todo whose tags, subtasks or comments
+// changed. Callers must hold r.mu.
+func (r *MemoryTodoRepository) bump(id uint) {
+	todo, ok := r.todos[id]
+	if !ok || todo.DeletedAt.Valid {
+		return
+	}
+
+	todo.Version++
+	todo.UpdatedAt = time.Now()
+	r.todos[id] = todo
+}
+
+// projectExists reports whether id is nil or names a stored project. Callers
+// must hold r.mu.
+func (r *MemoryTodoRepository) projectExists(id *uint) bool {
+	if id == nil {
+		return true
+	}
+	_, ok := r.projects[*id]
+
+	return ok
+}
+
+// forget removes a todo along with its tags, subtasks and comments. Callers
+// must hold r.mu.
+func (r *MemoryTodoRepository) forget(id uint) {
+	delete(r.todos, id)
+	delete(r.labels, id)
+	for subtaskID, subtask := range r.subtasks {
+		if subtask.TodoID == id {
+			delete(r.subtasks, subtaskID)
+		}
+	}
+	for commentID, comment := range r.comments {
+		if comment.TodoID == id {
+			delete(r.comments, commentID)
+		}
+	}
+	for shareID, share := range r.shares {
+		if share.TodoID != nil && *share.TodoID == id {
+			delete(r.shares, shareID)
+		}
+	}
+}
+
+// commentCounts maps todo ids to how many comments they have. Callers must
+// hold r.mu.
+func (r *MemoryTodoRepository) commentCounts() map[uint]int64 {
+	counts := map[uint]int64{}
+	for _, comment := range r.comments {
+		counts[comment.TodoID]++
+	}
+
+	return counts
+}
+
+// tagsOf returns the tags of a todo ordered by name. Callers must hold r.mu.
+func (r *MemoryTodoRepository) tagsOf(id uint) []models.Tag {
+	tags := []models.Tag{}
+	for tagID := range r.labels[id] {
+		tags = append(tags, r.tags[tagID])
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags
+}
+
+// filter returns copies of the stored todos, trashed ones included, matching
+// keep, ordered by id. Callers must hold r.mu.
+func (r *MemoryTodoRepository) filter(keep func(models.ToDo) bool) []models.ToDo {
+	todos := []models.ToDo{}
+	counts := r.commentCounts()
+	for _, todo := range r.todos {
+		todo.Tags = r.tagsOf(todo.ID)
+		todo.CommentCount = counts[todo.ID]
+		if keep(todo) {
+			todos = append(todos, todo)
+		}
+	}
+
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+
+	return todos
+}
+
+// matches reports whether todo passes the filters of query, ignoring its page.
+func matches(todo models.ToDo, query TodoQuery) bool {
+	if todo.DeletedAt.Valid != query.Trashed {
+		return false
+	}
+	if query.OwnerID != nil && todo.OwnerID != *query.OwnerID {
+		return false
+	}
+	if query.Access != nil && !query.Access.Allows(todo) {
+		return false
+	}
+	if query.Status != nil && todo.Status != *query.Status {
+		return false
+	}
+	if len(query.States) > 0 && !slices.Contains(query.States, todo.State) {
+		return false
+	}
+	if query.ProjectID != nil && (todo.ProjectID == nil || *todo.ProjectID != *query.ProjectID) {
+		return false
+	}
+	if query.SeriesID != nil && (todo.SeriesID == nil || *todo.SeriesID != *query.SeriesID) {
+		return false
+	}
+	if len(query.Priorities) > 0 && !slices.Contains(query.Priorities, todo.Priority) {
+		return false
+	}
+	if len(query.AnyTags) > 0 && countTagged(todo, query.AnyTags) == 0 {
+		return false
+	}
+	if len(query.AllTags) > 0 && countTagged(todo, query.AllTags) < len(uniqueNames(query.AllTags)) {
+		return false
+	}
+	if query.Filter != nil && !evalFilter(query.Filter, todo) {
+		return false
+	}
+
+	return true
+}
+
+// countTagged counts how many of names label todo.
+func countTagged(todo models.ToDo, names []string) int {
+	found := map[string]bool{}
+	for _, tag := range todo.Tags {
+		if slices.Contains(names, tag.Name) {
+			found[tag.Name] = true
+		}
+	}
+
+	return len(found)
+}
@@ -0,0 +1,263 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository keeps users, sessions and API keys in maps guarded by
+// a mutex.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	nextID uint
+	users  map[uint]models.User
+
+	nextSessionID uint
+	sessions      map[uint]models.Session
+
+	nextAPIKeyID uint
+	apiKeys      map[uint]models.APIKey
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		nextID:        1,
+		users:         make(map[uint]models.User),
+		nextSessionID: 1,
+		sessions:      make(map[uint]models.Session),
+		nextAPIKeyID:  1,
+		apiKeys:       make(map[uint]models.APIKey),
+	}
+}
+
+func (r *MemoryUserRepository) Create(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(user)
+}
+
+func (r *MemoryUserRepository) Register(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.countRole(models.RoleAdmin) == 0 {
+		user.Role = models.RoleAdmin
+	}
+
+	return r.create(user)
+}
+
+// create stores user; the caller holds the lock.
+func (r *MemoryUserRepository) create(user *models.User) error {
+	for _, existing := range r.users {
+		if existing.Username == user.Username || existing.Email == user.Email {
+			return ErrDuplicateUser
+		}
+	}
+
+	now := time.Now()
+	user.ID = r.nextID
+	if user.Role == "" {
+		user.Role = models.RoleEditor
+	}
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.nextID++
+
+	r.users[user.ID] = *user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) FindByID(id uint) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) FindByLogin(login string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == login || user.Email == login {
+			return &user, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) List() ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return users, nil
+}
+
+// countRole tells how many users have role; the caller holds the lock.
+func (r *MemoryUserRepository) countRole(role models.Role) int {
+	count := 0
+	for _, user := range r.users {
+		if user.Role == role {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r *MemoryUserRepository) SetRole(id uint, role models.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if user.Role == models.RoleAdmin && role != models.RoleAdmin && r.countRole(models.RoleAdmin) <= 1 {
+		return ErrLastAdmin
+	}
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) CreateSession(session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	session.ID = r.nextSessionID
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	r.nextSessionID++
+
+	r.sessions[session.ID] = *session
+
+	return nil
+}
+
+func (r *MemoryUserRepository) FindSession(tokenHash string, now time.Time) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if session.TokenHash == tokenHash && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			return &session, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) RotateSession(session *models.Session, tokenHash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.sessions[session.ID]
+	if !ok || stored.TokenHash != session.TokenHash || stored.RevokedAt != nil {
+		return ErrNotFound
+	}
+
+	stored.TokenHash, stored.ExpiresAt, stored.UpdatedAt = tokenHash, expiresAt, time.Now()
+	r.sessions[session.ID] = stored
+	*session = stored
+
+	return nil
+}
+
+func (r *MemoryUserRepository) RevokeSession(id uint, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &now
+		r.sessions[id] = session
+	}
+
+	return nil
+}
+
+func (r *MemoryUserRepository) CreateAPIKey(key *models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.ID = r.nextAPIKeyID
+	key.CreatedAt = time.Now()
+	r.nextAPIKeyID++
+
+	r.apiKeys[key.ID] = *key
+
+	return nil
+}
+
+func (r *MemoryUserRepository) ListAPIKeys(userID uint) ([]models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, key := range r.apiKeys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+
+	return keys, nil
+}
+
+func (r *MemoryUserRepository) FindAPIKey(keyHash string, now time.Time) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.apiKeys {
+		if key.KeyHash == keyHash && (key.ExpiresAt == nil || key.ExpiresAt.After(now)) {
+			return &key, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) TouchAPIKey(id uint, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.apiKeys[id]; ok {
+		key.LastUsedAt = &now
+		r.apiKeys[id] = key
+	}
+
+	return nil
+}
+
+func (r *MemoryUserRepository) DeleteAPIKey(userID uint, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.apiKeys[id]
+	if !ok || key.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.apiKeys, id)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import "example/Studying/models"
+
+// OwnedProjectRepository is a ProjectRepository limited to the projects of
+// one user and, WithShares, the projects shared with them. Projects of
+// anybody else look like they do not exist, and projects created through it
+// belong to the user. Shared projects can be renamed by editors; only the
+// owner of a project can delete it.
+type OwnedProjectRepository struct {
+	projects ProjectRepository
+	ownerID  uint
+	shares   ShareRepository
+}
+
+func NewOwnedProjectRepository(projects ProjectRepository, ownerID uint) *OwnedProjectRepository {
+	return &OwnedProjectRepository{projects: projects, ownerID: ownerID}
+}
+
+// WithShares makes projects shared with the user visible too.
+func (r *OwnedProjectRepository) WithShares(shares ShareRepository) *OwnedProjectRepository {
+	r.shares = shares
+	return r
+}
+
+func (r *OwnedProjectRepository) Create(project *models.Project) error {
+	project.OwnerID = r.ownerID
+	return r.projects.Create(project)
+}
+
+func (r *OwnedProjectRepository) List(query ProjectQuery) ([]models.Project, error) {
+	query.OwnerID, query.Shared = &r.ownerID, nil
+	if r.shares != nil {
+		shares, err := r.shares.ListForUser(r.ownerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			if share.ProjectID != nil {
+				query.Shared = append(query.Shared, *share.ProjectID)
+			}
+		}
+	}
+
+	return r.projects.List(query)
+}
+
+func (r *OwnedProjectRepository) FindByID(id uint) (*models.Project, error) {
+	project, err := r.projects.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := r.Role(project)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrNotFound
+	}
+
+	return project, nil
+}
+
+func (r *OwnedProjectRepository) Update(project *models.Project) error {
+	stored, err := r.FindByID(project.ID)
+	if err != nil {
+		return err
+	}
+
+	role, err := r.Role(stored)
+	if err != nil {
+		return err
+	}
+	if !role.Includes(models.ShareEditor) {
+		return ErrForbidden
+	}
+
+	return r.projects.Update(project)
+}
+
+func (r *OwnedProjectRepository) Delete(id uint, trash bool) error {
+	project, err := r.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if project.OwnerID != r.ownerID {
+		return ErrForbidden
+	}
+
+	return r.projects.Delete(id, trash)
+}
+
+// Role tells what the user may do with project: ShareOwner for their own
+// projects, the best role they were given for shared ones, and "" for the
+// rest.
+func (r *OwnedProjectRepository) Role(project *models.Project) (models.ShareRole, error) {
+	if project.OwnerID == r.ownerID {
+		return models.ShareOwner, nil
+	}
+	if r.shares == nil {
+		return "", nil
+	}
+
+	shares, err := r.shares.ListForUser(r.ownerID)
+	if err != nil {
+		return "", err
+	}
+
+	var role models.ShareRole
+	for _, share := range shares {
+		if share.ProjectID != nil && *share.ProjectID == project.ID && share.OwnerID == project.OwnerID && !role.Includes(share.Role) {
+			role = share.Role
+		}
+	}
+
+	return role, nil
+}
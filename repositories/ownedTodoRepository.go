@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	"errors"
+	"example/Studying/models"
+	"time"
+)
+
+// ErrForbidden means the todo or project is shared with the user, but not
+// in a way that allows the change.
+var ErrForbidden = errors.New("shared without this permission")
+
+// OwnedTodoRepository is a TodoRepository limited to the todos of one user
+// and, WithShares, the todos shared with them. Todos of anybody else look
+// like they do not exist, and todos created through it belong to the user.
+// Shared todos can be changed by editors and deleted, restored and purged
+// by owners; the trash only lists the user's own todos. A project is shared
+// with all of its todos, including the ones other members put there, and
+// WithProjects the owner of a project owns every todo in it too.
+type OwnedTodoRepository struct {
+	todos    TodoRepository
+	ownerID  uint
+	shares   ShareRepository
+	projects ProjectRepository
+}
+
+func NewOwnedTodoRepository(todos TodoRepository, ownerID uint) *OwnedTodoRepository {
+	return &OwnedTodoRepository{todos: todos, ownerID: ownerID}
+}
+
+// WithShares makes todos shared with the user visible too.
+func (r *OwnedTodoRepository) WithShares(shares ShareRepository) *OwnedTodoRepository {
+	r.shares = shares
+	return r
+}
+
+// WithProjects makes todos go only into projects the user owns or edits.
+// Other projects fail with ErrProjectNotFound, like missing ones. It also
+// shows the user the todos others put into the user's projects.
+func (r *OwnedTodoRepository) WithProjects(projects ProjectRepository) *OwnedTodoRepository {
+	r.projects = projects
+	return r
+}
+
+func (r *OwnedTodoRepository) Create(todo *models.ToDo) error {
+	if err := r.checkProject(todo.ProjectID); err != nil {
+		return err
+	}
+
+	todo.OwnerID = r.ownerID
+	return r.todos.Create(todo)
+}
+
+func (r *OwnedTodoRepository) List(query TodoQuery) ([]models.ToDo, error) {
+	query, err := r.scope(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.todos.List(query)
+}
+
+func (r *OwnedTodoRepository) Count(query TodoQuery) (int64, error) {
+	query, err := r.scope(query)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.todos.Count(query)
+}
+
+func (r *OwnedTodoRepository) Search(text string, query TodoQuery) ([]SearchHit, error) {
+	query, err := r.scope(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.todos.Search(text, query)
+}
+
+func (r *OwnedTodoRepository) FindByID(id uint) (*models.ToDo, error) {
+	return r.owned(r.todos.FindByID(id))
+}
+
+func (r *OwnedTodoRepository) Update(todo *models.ToDo) error {
+	stored, err := r.check(todo.ID, models.ShareEditor)
+	if err != nil {
+		return err
+	}
+	// A todo stays in its project even if the user can't file new ones
+	// there, for instance when only the todo is shared with them.
+	if !sameProject(stored.ProjectID, todo.ProjectID) {
+		if err := r.checkProject(todo.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	return r.todos.Update(todo)
+}
+
+func (r *OwnedTodoRepository) Delete(id uint, version uint) error {
+	if _, err := r.check(id, models.ShareOwner); err != nil {
+		return err
+	}
+
+	return r.todos.Delete(id, version)
+}
+
+func (r *OwnedTodoRepository) FindByIDUnscoped(id uint) (*models.ToDo, error) {
+	return r.owned(r.todos.FindByIDUnscoped(id))
+}
+
+func (r *OwnedTodoRepository) Restore(id uint) error {
+	if _, err := r.check(id, models.ShareOwner); err != nil {
+		return err
+	}
+
+	return r.todos.Restore(id)
+}
+
+func (r *OwnedTodoRepository) Purge(id uint, version uint) error {
+	if _, err := r.check(id, models.ShareOwner); err != nil {
+		return err
+	}
+
+	return r.todos.Purge(id, version)
+}
+
+// PurgeDeletedBefore purges the user's own expired todos one by one.
+func (r *OwnedTodoRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	trashed, err := r.List(TodoQuery{Trashed: true})
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, todo := range trashed {
+		if !todo.DeletedAt.Time.Before(cutoff) {
+			continue
+		}
+		if err := r.todos.Purge(todo.ID, 0); err != nil && !errors.Is(err, ErrNotFound) {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// AdjacentRank looks at the ranks of every todo. Ranks only order todos, so
+// neighbours belonging to somebody else still give a key in the right place.
+func (r *OwnedTodoRepository) AdjacentRank(rank string, next bool, exclude uint) (string, error) {
+	return r.todos.AdjacentRank(rank, next, exclude)
+}
+
+// Role tells what the user may do with todo: ShareOwner for their own
+// todos, the best role they were given for shared ones, and "" for the rest.
+func (r *OwnedTodoRepository) Role(todo *models.ToDo) (models.ShareRole, error) {
+	if todo.OwnerID == r.ownerID {
+		return models.ShareOwner, nil
+	}
+	owns, err := r.ownsProject(todo.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if owns {
+		return models.ShareOwner, nil
+	}
+	if r.shares == nil {
+		return "", nil
+	}
+
+	shares, err := r.shares.ListForUser(r.ownerID)
+	if err != nil {
+		return "", err
+	}
+
+	var role models.ShareRole
+	for _, share := range shares {
+		direct := share.TodoID != nil && *share.TodoID == todo.ID
+		inProject := share.ProjectID != nil && todo.ProjectID != nil && *share.ProjectID == *todo.ProjectID
+		if (direct || inProject) && !role.Includes(share.Role) {
+			role = share.Role
+		}
+	}
+
+	return role, nil
+}
+
+// ownsProject tells whether the project with id belongs to the user.
+func (r *OwnedTodoRepository) ownsProject(id *uint) (bool, error) {
+	if id == nil || r.projects == nil {
+		return false, nil
+	}
+
+	project, err := r.projects.FindByID(*id)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return project.OwnerID == r.ownerID, nil
+}
+
+// scope limits a listing to the user's todos and, unless it lists the
+// trash, to the ones in their projects and the ones shared with them.
+func (r *OwnedTodoRepository) scope(query TodoQuery) (TodoQuery, error) {
+	if (r.shares == nil && r.projects == nil) || query.Trashed {
+		query.OwnerID = &r.ownerID
+		return query, nil
+	}
+
+	access := &Access{UserID: r.ownerID}
+	if r.projects != nil {
+		projects, err := r.projects.List(ProjectQuery{OwnerID: &r.ownerID})
+		if err != nil {
+			return query, err
+		}
+		for _, project := range projects {
+			access.Projects = append(access.Projects, project.ID)
+		}
+	}
+	if r.shares != nil {
+		shares, err := r.shares.ListForUser(r.ownerID)
+		if err != nil {
+			return query, err
+		}
+		for _, share := range shares {
+			if share.TodoID != nil {
+				access.TodoIDs = append(access.TodoIDs, *share.TodoID)
+			}
+			if share.ProjectID != nil {
+				access.Projects = append(access.Projects, *share.ProjectID)
+			}
+		}
+	}
+	query.Access = access
+
+	return query, nil
+}
+
+func (r *OwnedTodoRepository) owned(todo *models.ToDo, err error) (*models.ToDo, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := r.Role(todo)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrNotFound
+	}
+
+	return todo, nil
+}
+
+// check makes sure the user can see the live or trashed todo with id and
+// has role for it, and returns the todo as it is stored.
+func (r *OwnedTodoRepository) check(id uint, role models.ShareRole) (*models.ToDo, error) {
+	todo, err := r.FindByIDUnscoped(id)
+	if err != nil {
+		return nil, err
+	}
+
+	granted, err := r.Role(todo)
+	if err != nil {
+		return nil, err
+	}
+	if !granted.Includes(role) {
+		return nil, ErrForbidden
+	}
+
+	return todo, nil
+}
+
+// checkProject makes sure the user may file todos into the project with id,
+// if any: they have to own it or edit it through a share.
+func (r *OwnedTodoRepository) checkProject(id *uint) error {
+	if id == nil || r.projects == nil {
+		return nil
+	}
+
+	projects := NewOwnedProjectRepository(r.projects, r.ownerID).WithShares(r.shares)
+	project, err := projects.FindByID(*id)
+	if errors.Is(err, ErrNotFound) {
+		return ErrProjectNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	role, err := projects.Role(project)
+	if err != nil {
+		return err
+	}
+	if !role.Includes(models.ShareEditor) {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+func sameProject(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
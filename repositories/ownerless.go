@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"example/Studying/models"
+
+	"gorm.io/gorm"
+)
+
+// ClaimOwnerless gives the todos, series, projects and tags created before
+// there were users to the user with ownerID, the first admin. A tag whose
+// name they already use is merged into their tag.
+func ClaimOwnerless(tx *gorm.DB, ownerID uint) error {
+	for _, model := range []interface{}{&models.ToDo{}, &models.Series{}, &models.Project{}} {
+		if err := tx.Unscoped().Model(model).Where("owner_id = 0").UpdateColumn("owner_id", ownerID).Error; err != nil {
+			return err
+		}
+	}
+
+	taken := tx.Model(&models.Tag{}).Select("name").Where("owner_id = ?", ownerID)
+	if err := tx.Model(&models.Tag{}).Where("owner_id = 0 AND name NOT IN (?)", taken).UpdateColumn("owner_id", ownerID).Error; err != nil {
+		return err
+	}
+
+	var duplicates []models.Tag
+	if err := tx.Where("owner_id = 0").Find(&duplicates).Error; err != nil {
+		return err
+	}
+	for _, duplicate := range duplicates {
+		var kept models.Tag
+		if err := tx.Where("owner_id = ? AND name = ?", ownerID, duplicate.Name).First(&kept).Error; err != nil {
+			return err
+		}
+
+		relabelled := tx.Table("todo_tags").Select("todo_id").Where("tag_id = ?", kept.ID)
+		if err := tx.Table("todo_tags").Where("tag_id = ? AND todo_id NOT IN (?)", duplicate.ID, relabelled).UpdateColumn("tag_id", kept.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM todo_tags WHERE tag_id = ?", duplicate.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&duplicate).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"errors"
+	"example/Studying/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// ProjectQuery describes which projects a listing should return. OwnerID
+// limits it to the projects of one user, plus the ones with ids in Shared.
+type ProjectQuery struct {
+	OwnerID *uint
+	Shared  []uint
+}
+
+// ProjectRepository stores projects. Todos refer to them by ProjectID, and
+// TodoRepository rejects ids of projects that do not exist.
+type ProjectRepository interface {
+	Create(project *models.Project) error
+	// List returns the projects matching query ordered by name.
+	List(query ProjectQuery) ([]models.Project, error)
+	FindByID(id uint) (*models.Project, error)
+	Update(project *models.Project) error
+	// Delete removes a project and its shares. The live todos of its owner
+	// move to the trash if trash is set and to the inbox otherwise; todos of
+	// other users, trashed ones and the occurrences recurring todos will
+	// create always lose the project.
+	Delete(id uint, trash bool) error
+}
+
+type GormProjectRepository struct {
+	db *gorm.DB
+}
+
+func NewGormProjectRepository(db *gorm.DB) *GormProjectRepository {
+	return &GormProjectRepository{db: db}
+}
+
+func (r *GormProjectRepository) Create(project *models.Project) error {
+	return r.db.Create(project).Error
+}
+
+func (r *GormProjectRepository) List(query ProjectQuery) ([]models.Project, error) {
+	tx := r.db
+	if query.OwnerID != nil {
+		if len(query.Shared) > 0 {
+			tx = tx.Where("owner_id = ? OR id IN ?", *query.OwnerID, query.Shared)
+		} else {
+			tx = tx.Where("owner_id = ?", *query.OwnerID)
+		}
+	}
+
+	projects := []models.Project{}
+	if err := tx.Order("name").Order("id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (r *GormProjectRepository) FindByID(id uint) (*models.Project, error) {
+	var project models.Project
+
+	if err := r.db.First(&project, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &project, nil
+}
+
+func (r *GormProjectRepository) Update(project *models.Project) error {
+	result := r.db.Model(project).Updates(map[string]interface{}{
+		"Name":        project.Name,
+		"Description": project.Description,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *GormProjectRepository) Delete(id uint, trash bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.First(&project, id).Error; err != nil {
+			return notFound(err)
+		}
+
+		owned := map[string]interface{}{
+			"ProjectID": nil,
+			"Version":   gorm.Expr("version + 1"),
+		}
+		if trash {
+			owned["DeletedAt"] = time.Now()
+		}
+		if err := tx.Model(&models.ToDo{}).Where("project_id = ? AND owner_id = ?", id, project.OwnerID).Updates(owned).Error; err != nil {
+			return err
+		}
+		live := map[string]interface{}{
+			"ProjectID": nil,
+			"Version":   gorm.Expr("version + 1"),
+		}
+		if err := tx.Model(&models.ToDo{}).Where("project_id = ?", id).Updates(live).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.ToDo{}).Where("project_id = ?", id).Update("project_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Series{}).Where("project_id = ?", id).Update("project_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&models.Share{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Project{}, id).Error
+	})
+}
+
+// checkProject makes sure a todo refers to an existing project, if any.
+func checkProject(db *gorm.DB, id *uint) error {
+	if id == nil {
+		return nil
+	}
+
+	err := db.First(&models.Project{}, *id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrProjectNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"example/Studying/models"
+
+	"gorm.io/gorm"
+)
+
+// SeriesRepository stores the templates of recurring todos.
+type SeriesRepository interface {
+	Create(series *models.Series) error
+	FindByID(id uint) (*models.Series, error)
+	Update(series *models.Series) error
+	// Advance creates next, the occurrence following the latest one, and
+	// makes it the latest. Nothing is created, and ErrVersionConflict is
+	// returned, if the latest occurrence is no longer series.LatestID.
+	Advance(series *models.Series, next *models.ToDo) error
+}
+
+type GormSeriesRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSeriesRepository(db *gorm.DB) *GormSeriesRepository {
+	return &GormSeriesRepository{db: db}
+}
+
+func (r *GormSeriesRepository) Create(series *models.Series) error {
+	return r.db.Create(series).Error
+}
+
+func (r *GormSeriesRepository) FindByID(id uint) (*models.Series, error) {
+	var series models.Series
+
+	if err := r.db.First(&series, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &series, nil
+}
+
+func (r *GormSeriesRepository) Update(series *models.Series) error {
+	result := r.db.Model(series).Updates(map[string]interface{}{
+		"RRule":       series.RRule,
+		"Start":       series.Start,
+		"LatestID":    series.LatestID,
+		"Title":       series.Title,
+		"Body":        series.Body,
+		"Priority":    series.Priority,
+		"ProjectID":   series.ProjectID,
+		"StartBefore": series.StartBefore,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *GormSeriesRepository) Advance(series *models.Series, next *models.ToDo) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewGormTodoRepository(tx).Create(next); err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Series{}).
+			Where("id = ? AND latest_id = ?", series.ID, series.LatestID).
+			Update("latest_id", next.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	series.LatestID = next.ID
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"errors"
+	"example/Studying/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateShare means the todo or project is already shared with the user.
+var ErrDuplicateShare = errors.New("share already exists")
+
+// ShareRepository stores who todos and projects are shared with.
+type ShareRepository interface {
+	// Create fails with ErrDuplicateShare if the user already has a share of
+	// the same todo, or of the same owner's project.
+	Create(share *models.Share) error
+	FindByID(id uint) (*models.Share, error)
+	// ListForTodo returns the shares of the todo with todoID, oldest first.
+	ListForTodo(todoID uint) ([]models.Share, error)
+	// ListForProject returns the shares of ownerID's todos in the project
+	// with projectID, oldest first.
+	ListForProject(ownerID uint, projectID uint) ([]models.Share, error)
+	// ListForUser returns everything shared with the user with userID.
+	ListForUser(userID uint) ([]models.Share, error)
+	Delete(id uint) error
+}
+
+type GormShareRepository struct {
+	db *gorm.DB
+}
+
+func NewGormShareRepository(db *gorm.DB) *GormShareRepository {
+	return &GormShareRepository{db: db}
+}
+
+func (r *GormShareRepository) Create(share *models.Share) error {
+	return translate(r.db, r.db.Create(share).Error, ErrDuplicateShare)
+}
+
+func (r *GormShareRepository) FindByID(id uint) (*models.Share, error) {
+	var share models.Share
+
+	if err := r.db.First(&share, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &share, nil
+}
+
+func (r *GormShareRepository) ListForTodo(todoID uint) ([]models.Share, error) {
+	return r.list(r.db.Where("todo_id = ?", todoID))
+}
+
+func (r *GormShareRepository) ListForProject(ownerID uint, projectID uint) ([]models.Share, error) {
+	return r.list(r.db.Where("owner_id = ? AND project_id = ?", ownerID, projectID))
+}
+
+func (r *GormShareRepository) ListForUser(userID uint) ([]models.Share, error) {
+	return r.list(r.db.Where("user_id = ?", userID))
+}
+
+func (r *GormShareRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.Share{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *GormShareRepository) list(tx *gorm.DB) ([]models.Share, error) {
+	shares := []models.Share{}
+	if err := tx.Order("id").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
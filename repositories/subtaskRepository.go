@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// SubtaskRepository stores the checklists of todos. Every change bumps the
+// version of the todo, whose completion it affects, and fails with
+// ErrNotFound if the todo is not live.
+type SubtaskRepository interface {
+	// Create inserts subtask at its Position, or appends it if Position is 0.
+	Create(subtask *models.Subtask) error
+	// List returns the subtasks of a todo in order.
+	List(todoID uint) ([]models.Subtask, error)
+	FindByID(todoID uint, id uint) (*models.Subtask, error)
+	// Update saves subtask and moves it to its Position if that changed.
+	Update(subtask *models.Subtask) error
+	Delete(todoID uint, id uint) error
+}
+
+type GormSubtaskRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSubtaskRepository(db *gorm.DB) *GormSubtaskRepository {
+	return &GormSubtaskRepository{db: db}
+}
+
+func (r *GormSubtaskRepository) Create(subtask *models.Subtask) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		order, err := r.order(tx, subtask.TodoID)
+		if err != nil {
+			return err
+		}
+
+		position := subtask.Position
+		subtask.Position = len(order) + 1
+		if err := tx.Create(subtask).Error; err != nil {
+			return err
+		}
+
+		if position > 0 {
+			if err := renumber(tx, placeAt(append(order, subtask.ID), subtask.ID, position)); err != nil {
+				return err
+			}
+			subtask.Position = min(position, len(order)+1)
+		}
+
+		return bumpVersion(tx.Where("id = ?", subtask.TodoID))
+	})
+}
+
+func (r *GormSubtaskRepository) List(todoID uint) ([]models.Subtask, error) {
+	subtasks := []models.Subtask{}
+	if err := r.db.Where("todo_id = ?", todoID).Order("position").Find(&subtasks).Error; err != nil {
+		return nil, err
+	}
+
+	return subtasks, nil
+}
+
+func (r *GormSubtaskRepository) FindByID(todoID uint, id uint) (*models.Subtask, error) {
+	var subtask models.Subtask
+
+	if err := r.db.Where("todo_id = ?", todoID).First(&subtask, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &subtask, nil
+}
+
+func (r *GormSubtaskRepository) Update(subtask *models.Subtask) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		order, err := r.order(tx, subtask.TodoID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(order, subtask.ID) {
+			return ErrNotFound
+		}
+
+		result := tx.Model(subtask).Updates(map[string]interface{}{
+			"Title": subtask.Title,
+			"Done":  subtask.Done,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		order = placeAt(order, subtask.ID, subtask.Position)
+		if err := renumber(tx, order); err != nil {
+			return err
+		}
+		subtask.Position = slices.Index(order, subtask.ID) + 1
+
+		return bumpVersion(tx.Where("id = ?", subtask.TodoID))
+	})
+}
+
+func (r *GormSubtaskRepository) Delete(todoID uint, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		order, err := r.order(tx, todoID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(order, id) {
+			return ErrNotFound
+		}
+
+		if err := tx.Delete(&models.Subtask{}, id).Error; err != nil {
+			return err
+		}
+		if err := renumber(tx, slices.DeleteFunc(order, func(other uint) bool { return other == id })); err != nil {
+			return err
+		}
+
+		return bumpVersion(tx.Where("id = ?", todoID))
+	})
+}
+
+// order returns the subtask ids of a live todo in order.
+func (r *GormSubtaskRepository) order(tx *gorm.DB, todoID uint) ([]uint, error) {
+	if err := tx.First(&models.ToDo{}, todoID).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	var ids []uint
+	err := tx.Model(&models.Subtask{}).Where("todo_id = ?", todoID).Order("position").Order("id").Pluck("id", &ids).Error
+
+	return ids, err
+}
+
+// renumber stores the positions implied by order.
+func renumber(tx *gorm.DB, order []uint) error {
+	for i, id := range order {
+		if err := tx.Model(&models.Subtask{}).Where("id = ? AND position <> ?", id, i+1).Update("position", i+1).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// placeAt moves id within order to the 1-based position, clamped to the
+// ends. A position of 0 leaves order as it is.
+func placeAt(order []uint, id uint, position int) []uint {
+	if position <= 0 {
+		return order
+	}
+
+	order = slices.DeleteFunc(order, func(other uint) bool { return other == id })
+	index := min(position-1, len(order))
+
+	return slices.Insert(order, index, id)
+}
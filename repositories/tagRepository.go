@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"errors"
+	"example/Studying/models"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDuplicateTag = errors.New("tag already exists")
+
+// TagRepository stores tags and which todos they label. Changing the tags of
+// a todo, directly or by renaming or deleting a tag, bumps the todo's version
+// because its representation changes.
+type TagRepository interface {
+	// Create fails with ErrDuplicateTag if the owner already has a tag with
+	// the name.
+	Create(tag *models.Tag) error
+	// List returns the tags of the user with ownerID ordered by name.
+	List(ownerID uint) ([]models.Tag, error)
+	FindByID(id uint) (*models.Tag, error)
+	Update(tag *models.Tag) error
+	// Delete removes the tag and detaches it from every todo.
+	Delete(id uint) error
+	// Attach labels a live todo with a tag; attaching it twice is a no-op.
+	Attach(todoID uint, tagID uint) error
+	// Detach removes a tag from a live todo, or returns ErrNotFound if the
+	// todo did not have it.
+	Detach(todoID uint, tagID uint) error
+}
+
+// todoTag is a row of the join table behind models.ToDo.Tags.
+type todoTag struct {
+	TodoID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey"`
+}
+
+func (todoTag) TableName() string {
+	return "todo_tags"
+}
+
+type GormTagRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTagRepository(db *gorm.DB) *GormTagRepository {
+	return &GormTagRepository{db: db}
+}
+
+func (r *GormTagRepository) Create(tag *models.Tag) error {
+	return translate(r.db, r.db.Create(tag).Error, ErrDuplicateTag)
+}
+
+func (r *GormTagRepository) List(ownerID uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if err := r.db.Where("owner_id = ?", ownerID).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *GormTagRepository) FindByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+
+	if err := r.db.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (r *GormTagRepository) Update(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(tag).Update("name", tag.Name)
+		if err := translate(tx, result.Error, ErrDuplicateTag); err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return bumpTagged(tx, tag.ID)
+	})
+}
+
+func (r *GormTagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpTagged(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&todoTag{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (r *GormTagRepository) Attach(todoID uint, tagID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.ToDo{}, todoID).Error; err != nil {
+			return notFound(err)
+		}
+		if err := tx.First(&models.Tag{}, tagID).Error; err != nil {
+			return notFound(err)
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&todoTag{TodoID: todoID, TagID: tagID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return bumpVersion(tx.Where("id = ?", todoID))
+	})
+}
+
+func (r *GormTagRepository) Detach(todoID uint, tagID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.ToDo{}, todoID).Error; err != nil {
+			return notFound(err)
+		}
+
+		result := tx.Where("todo_id = ? AND tag_id = ?", todoID, tagID).Delete(&todoTag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return bumpVersion(tx.Where("id = ?", todoID))
+	})
+}
+
+// bumpTagged bumps the version of every todo labelled with the tag.
+func bumpTagged(tx *gorm.DB, tagID uint) error {
+	return bumpVersion(tx.Where("id IN (?)", tx.Model(&todoTag{}).Select("todo_id").Where("tag_id = ?", tagID)))
+}
+
+// bumpVersion bumps the version of the todos tx selects.
+func bumpVersion(tx *gorm.DB) error {
+	return tx.Model(&models.ToDo{}).Update("version", gorm.Expr("version + 1")).Error
+}
+
+// taggedWith selects the ids of todos labelled with any of names, or with
+// all of them if all is set.
+func taggedWith(db *gorm.DB, names []string, all bool) *gorm.DB {
+	tx := db.Model(&todoTag{}).
+		Select("todo_tags.todo_id").
+		Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+		Where("tags.name IN ?", names)
+	if all {
+		tx = tx.Group("todo_tags.todo_id").Having("COUNT(DISTINCT tags.name) = ?", len(uniqueNames(names)))
+	}
+
+	return tx
+}
+
+// loadTags fills in the tags of todos with a single query.
+func loadTags(db *gorm.DB, todos []models.ToDo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	var rows []struct {
+		TodoID uint
+		models.Tag
+	}
+	err := db.Model(&todoTag{}).
+		Select("todo_tags.todo_id, tags.*").
+		Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+		Where("todo_tags.todo_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	tags := map[uint][]models.Tag{}
+	for _, row := range rows {
+		tags[row.TodoID] = append(tags[row.TodoID], row.Tag)
+	}
+	for i := range todos {
+		todos[i].Tags = tags[todos[i].ID]
+		if todos[i].Tags == nil {
+			todos[i].Tags = []models.Tag{}
+		}
+	}
+
+	return nil
+}
+
+func uniqueNames(names []string) []string {
+	unique := slices.Clone(names)
+	slices.Sort(unique)
+
+	return slices.Compact(unique)
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// translate maps dialect specific errors to the repository's own, a unique
+// violation to duplicate.
+func translate(db *gorm.DB, err error, duplicate error) error {
+	if err == nil {
+		return nil
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return duplicate
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"example/Studying/filters"
+	"example/Studying/models"
+	"time"
+)
+
+// todoField maps an API field name to its column and to the value it has on
+// a todo. The map below is the whitelist for sorting and filtering: no other
+// identifier ever reaches ORDER BY or WHERE.
+type todoField struct {
+	column   string
+	value    func(todo models.ToDo) interface{}
+	sortable bool
+}
+
+var todoFields = map[string]todoField{
+	"id":           {"id", func(todo models.ToDo) interface{} { return todo.ID }, true},
+	"title":        {"title", func(todo models.ToDo) interface{} { return todo.Title }, true},
+	"body":         {"body", func(todo models.ToDo) interface{} { return todo.Body }, false},
+	"status":       {"status", func(todo models.ToDo) interface{} { return todo.Status }, true},
+	"state":        {"state", func(todo models.ToDo) interface{} { return string(todo.State) }, false},
+	"priority":     {"priority", func(todo models.ToDo) interface{} { return todo.Priority }, true},
+	"created_at":   {"created_at", func(todo models.ToDo) interface{} { return todo.CreatedAt }, true},
+	"updated_at":   {"updated_at", func(todo models.ToDo) interface{} { return todo.UpdatedAt }, true},
+	"start_at":     {"start_at", func(todo models.ToDo) interface{} { return todo.StartAt }, true},
+	"due_at":       {"due_at", func(todo models.ToDo) interface{} { return todo.DueAt }, true},
+	"completed_at": {"completed_at", func(todo models.ToDo) interface{} { return todo.CompletedAt }, true},
+	"rank":         {"rank", func(todo models.ToDo) interface{} { return todo.Rank }, true},
+}
+
+// smartKeys is the default ordering: most urgent first, then earliest due,
+// with undated todos after dated ones in creation order. The extra keys are
+// expressions rather than plain columns so that they are never NULL and the
+// keyset conditions stay valid.
+var smartKeys = []struct {
+	SortField
+	todoField
+}{
+	{SortField{Field: "priority", Desc: true}, todoFields["priority"]},
+	{SortField{Field: "due_missing"}, todoField{
+		column: "CASE WHEN due_at IS NULL THEN 1 ELSE 0 END",
+		value: func(todo models.ToDo) interface{} {
+			if todo.DueAt == nil {
+				return uint(1)
+			}
+			return uint(0)
+		},
+	}},
+	{SortField{Field: "due"}, todoField{
+		column: "COALESCE(due_at, created_at)",
+		value: func(todo models.ToDo) interface{} {
+			if todo.DueAt == nil {
+				return todo.CreatedAt
+			}
+			return *todo.DueAt
+		},
+	}},
+}
+
+// nullable reports whether the field may have no value. Comparisons on a
+// missing value are false, and so never match.
+func (f todoField) nullable() bool {
+	_, ok := f.value(models.ToDo{}).(*time.Time)
+	return ok
+}
+
+// nullsLast splits a nullable date field into two keys that are never NULL,
+// the same way smartKeys does for due_at: whether the date is missing, so
+// todos without one come last in either direction, and the date itself,
+// falling back to created_at to order the undated ones.
+func (f todoField) nullsLast() (missing todoField, date todoField) {
+	missing = todoField{
+		column: "CASE WHEN " + f.column + " IS NULL THEN 1 ELSE 0 END",
+		value: func(todo models.ToDo) interface{} {
+			if f.value(todo).(*time.Time) == nil {
+				return uint(1)
+			}
+			return uint(0)
+		},
+	}
+	date = todoField{
+		column: "COALESCE(" + f.column + ", created_at)",
+		value: func(todo models.ToDo) interface{} {
+			if at := f.value(todo).(*time.Time); at != nil {
+				return *at
+			}
+			return todo.CreatedAt
+		},
+	}
+
+	return missing, date
+}
+
+// TodoFilterSchema describes the todo fields filter expressions may use.
+func TodoFilterSchema() filters.Schema {
+	schema := filters.Schema{}
+	for name, field := range todoFields {
+		switch field.value(models.ToDo{}).(type) {
+		case models.Priority:
+			// Filtered by name through TodoQuery.Priorities instead.
+			continue
+		case uint:
+			schema[name] = filters.Number
+		case bool:
+			schema[name] = filters.Bool
+		case time.Time, *time.Time:
+			schema[name] = filters.Time
+		default:
+			schema[name] = filters.String
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"example/Studying/filters"
+	"example/Studying/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var sqlOps = map[filters.Op]string{
+	filters.Eq:      "=",
+	filters.NotEq:   "<>",
+	filters.Less:    "<",
+	filters.LessEq:  "<=",
+	filters.Greater: ">",
+	filters.GreatEq: ">=",
+}
+
+// filterCondition translates a parsed filter into a parameterised WHERE
+// condition. Columns come from todoFields; every literal becomes an argument.
+func filterCondition(node filters.Node) (string, []interface{}) {
+	switch node := node.(type) {
+	case filters.And:
+		left, leftArgs := filterCondition(node.Left)
+		right, rightArgs := filterCondition(node.Right)
+		return "(" + left + " AND " + right + ")", append(leftArgs, rightArgs...)
+	case filters.Or:
+		left, leftArgs := filterCondition(node.Left)
+		right, rightArgs := filterCondition(node.Right)
+		return "(" + left + " OR " + right + ")", append(leftArgs, rightArgs...)
+	case filters.Not:
+		expr, args := filterCondition(node.Expr)
+		return "NOT " + expr, args
+	case filters.Comparison:
+		field := todoFields[node.Field]
+		column := field.column
+		if node.Op == filters.Contains {
+			// LOWER folds every letter on SQLite too, see initializers.
+			pattern := "%" + escapeLike(strings.ToLower(node.Value.(string))) + "%"
+			return "(LOWER(" + column + ") LIKE ? ESCAPE '\\')", []interface{}{pattern}
+		}
+
+		value := node.Value
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC()
+		}
+		// Spelling out IS NOT NULL makes a missing value compare false
+		// instead of unknown, so NOT treats it the same as evalFilter.
+		if field.nullable() {
+			return "(" + column + " IS NOT NULL AND " + column + " " + sqlOps[node.Op] + " ?)", []interface{}{value}
+		}
+		return "(" + column + " " + sqlOps[node.Op] + " ?)", []interface{}{value}
+	}
+
+	panic(fmt.Sprintf("repositories: unknown filter node %T", node))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// evalFilter is the in-memory counterpart of filterCondition.
+func evalFilter(node filters.Node, todo models.ToDo) bool {
+	switch node := node.(type) {
+	case filters.And:
+		return evalFilter(node.Left, todo) && evalFilter(node.Right, todo)
+	case filters.Or:
+		return evalFilter(node.Left, todo) || evalFilter(node.Right, todo)
+	case filters.Not:
+		return !evalFilter(node.Expr, todo)
+	case filters.Comparison:
+		value := todoFields[node.Field].value(todo)
+		if t, ok := value.(*time.Time); ok {
+			if t == nil {
+				return false
+			}
+			value = *t
+		}
+		if node.Op == filters.Contains {
+			return strings.Contains(strings.ToLower(value.(string)), strings.ToLower(node.Value.(string)))
+		}
+
+		c := compareValues(value, node.Value)
+		switch node.Op {
+		case filters.Eq:
+			return c == 0
+		case filters.NotEq:
+			return c != 0
+		case filters.Less:
+			return c < 0
+		case filters.LessEq:
+			return c <= 0
+		case filters.Greater:
+			return c > 0
+		case filters.GreatEq:
+			return c >= 0
+		}
+	}
+
+	panic(fmt.Sprintf("repositories: unknown filter node %T", node))
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"example/Studying/filters"
+	"example/Studying/models"
+	"slices"
+)
+
+// TodoQuery describes which todos a listing should return and in what order.
+// Filter must have been parsed with TodoFilterSchema. ProjectID limits the
+// listing to one project, OwnerID to the todos of one user and Access to the
+// todos a user can see. Non-empty States and Priorities keep todos in any of
+// the given states or with any of the priorities; AnyTags and AllTags keep
+// todos labelled with any or all of the named tags. SeriesID keeps the
+// occurrences of one recurring series. An empty Sort orders by priority,
+// then due date. Trashed lists soft-deleted todos instead of live ones.
+type TodoQuery struct {
+	Trashed    bool
+	OwnerID    *uint
+	Access     *Access
+	Status     *bool
+	States     []models.State
+	ProjectID  *uint
+	SeriesID   *uint
+	Priorities []models.Priority
+	AnyTags    []string
+	AllTags    []string
+	Filter     filters.Node
+	Sort       []SortField
+	Page       Page
+}
+
+// Access is what a user can see: their own todos, the todos in TodoIDs and
+// every todo in one of Projects, whoever owns it.
+type Access struct {
+	UserID   uint
+	TodoIDs  []uint
+	Projects []uint
+}
+
+// Allows reports whether todo is one the user can see.
+func (a *Access) Allows(todo models.ToDo) bool {
+	if todo.OwnerID == a.UserID || slices.Contains(a.TodoIDs, todo.ID) {
+		return true
+	}
+	if todo.ProjectID == nil {
+		return false
+	}
+
+	return slices.Contains(a.Projects, *todo.ProjectID)
+}
+
+// Page selects a window of a sorted listing. After/Before are keyset bounds;
+// Before pages backwards but results are still returned in sort order.
+// A zero Limit means no limit.
+type Page struct {
+	Limit  int
+	Offset int
+	After  Cursor
+	Before Cursor
+}
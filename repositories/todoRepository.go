@@ -0,0 +1,478 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"example/Studying/models"
+	"example/Studying/ranking"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound        = errors.New("record not found")
+	ErrVersionConflict = errors.New("record was modified concurrently")
+)
+
+type TodoRepository interface {
+	Create(todo *models.ToDo) error
+	List(query TodoQuery) ([]models.ToDo, error)
+	Count(query TodoQuery) (int64, error)
+	Search(text string, query TodoQuery) ([]SearchHit, error)
+	FindByID(id uint) (*models.ToDo, error)
+	// Update saves todo only if the stored version still equals
+	// todo.Version, and bumps the version on success. Create and Update
+	// fail with ErrProjectNotFound if todo.ProjectID names no project.
+	Update(todo *models.ToDo) error
+	// Delete moves the todo with id to the trash; a non-zero version must
+	// match the stored one.
+	Delete(id uint, version uint) error
+
+	// FindByIDUnscoped finds a todo whether or not it is in the trash.
+	FindByIDUnscoped(id uint) (*models.ToDo, error)
+	// Restore takes a todo out of the trash and bumps its version.
+	Restore(id uint) error
+	// Purge permanently removes a live or trashed todo; a non-zero version
+	// must match the stored one.
+	Purge(id uint, version uint) error
+	// PurgeDeletedBefore permanently removes todos trashed before cutoff.
+	PurgeDeletedBefore(cutoff time.Time) (int64, error)
+
+	// AdjacentRank returns the closest rank after (if next) or before rank
+	// held by any todo but exclude, trashed ones included, or "" if there is
+	// none. An empty rank is past the end of the order, so
+	// AdjacentRank("", false, 0) is the last rank in use. Create appends
+	// todos without a rank to the end of the manual order.
+	AdjacentRank(rank string, next bool, exclude uint) (string, error)
+}
+
+type GormTodoRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTodoRepository(db *gorm.DB) *GormTodoRepository {
+	return &GormTodoRepository{db: db}
+}
+
+func (r *GormTodoRepository) Create(todo *models.ToDo) error {
+	if err := checkProject(r.db, todo.ProjectID); err != nil {
+		return err
+	}
+
+	if todo.Rank == "" {
+		last, err := r.AdjacentRank("", false, 0)
+		if err != nil {
+			return err
+		}
+		todo.Rank = ranking.After(last)
+	}
+
+	defaultState(todo)
+	todo.Version = 1
+	return r.db.Create(todo).Error
+}
+
+// defaultState gives a todo created without a state the one its status
+// implies, the same way existing rows were migrated.
+func defaultState(todo *models.ToDo) {
+	switch {
+	case todo.State != "":
+	case todo.Status:
+		todo.State = models.StateDone
+	default:
+		todo.State = models.StateBacklog
+	}
+}
+
+func (r *GormTodoRepository) List(query TodoQuery) ([]models.ToDo, error) {
+	var todos []models.ToDo
+
+	fields, keys, err := orderKeys(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.where(query)
+
+	page := query.Page
+	if page.After != nil {
+		condition, args, err := keysetCondition(page.After, fields, keys, false)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(condition, args...)
+	}
+
+	backward := page.Before != nil
+	if backward {
+		condition, args, err := keysetCondition(page.Before, fields, keys, true)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(condition, args...)
+	}
+
+	for i, key := range keys {
+		tx = tx.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: key.column, Raw: true},
+			Desc:   fields[i].Desc != backward,
+		})
+	}
+	tx = paginate(tx, page)
+
+	if err := tx.Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	if err := loadRelations(r.db, todos); err != nil {
+		return nil, err
+	}
+
+	if backward {
+		reverse(todos)
+	}
+
+	return todos, nil
+}
+
+func (r *GormTodoRepository) Count(query TodoQuery) (int64, error) {
+	var total int64
+
+	if err := r.where(query).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Search ranks todos matching text. On Postgres it uses the search_vector
+// column created by initializers.MigrateDB; other dialects fall back to a
+// substring match. Only the filters, Limit and Offset of query apply.
+func (r *GormTodoRepository) Search(text string, query TodoQuery) ([]SearchHit, error) {
+	if r.db.Dialector.Name() == "postgres" {
+		return r.fullTextSearch(text, query)
+	}
+
+	pattern := "%" + escapeLike(strings.ToLower(text)) + "%"
+	tx := r.where(query).
+		Where("(LOWER(title) LIKE ? ESCAPE '\\' OR LOWER(body) LIKE ? ESCAPE '\\')", pattern, pattern).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "CASE WHEN LOWER(title) LIKE ? ESCAPE '\\' THEN 2 ELSE 0 END + CASE WHEN LOWER(body) LIKE ? ESCAPE '\\' THEN 1 ELSE 0 END DESC, id",
+			Vars: []interface{}{pattern, pattern},
+		}})
+	tx = paginate(tx, query.Page)
+
+	var todos []models.ToDo
+	if err := tx.Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	if err := loadRelations(r.db, todos); err != nil {
+		return nil, err
+	}
+
+	hits := make([]SearchHit, 0, len(todos))
+	for _, todo := range todos {
+		if hit, ok := substringHit(todo, text); ok {
+			hits = append(hits, hit)
+		}
+	}
+
+	return hits, nil
+}
+
+func (r *GormTodoRepository) fullTextSearch(text string, query TodoQuery) ([]SearchHit, error) {
+	const tsquery = "websearch_to_tsquery('" + searchConfig + "', ?)"
+	// The document is HTML-escaped before ts_headline wraps matches, so
+	// the only markup in a snippet is the <mark> tags.
+	const document = "replace(replace(replace(concat_ws(' ', title, body), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+
+	var rows []struct {
+		models.ToDo
+		Score   float64
+		Snippet string
+	}
+
+	tx := r.where(query).
+		Select("to_dos.*, ts_rank(search_vector, "+tsquery+") AS score, "+
+			"ts_headline('"+searchConfig+"', "+document+", "+tsquery+", "+
+			"'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=8, MaxFragments=2') AS snippet", text, text).
+		Where("search_vector @@ "+tsquery, text).
+		Order("score DESC").
+		Order("id")
+	tx = paginate(tx, query.Page)
+
+	if err := tx.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	todos := make([]models.ToDo, len(rows))
+	for i, row := range rows {
+		todos[i] = row.ToDo
+	}
+	if err := loadRelations(r.db, todos); err != nil {
+		return nil, err
+	}
+
+	hits := make([]SearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = SearchHit{Todo: todos[i], Rank: row.Score, Snippet: row.Snippet}
+	}
+
+	return hits, nil
+}
+
+func (r *GormTodoRepository) FindByID(id uint) (*models.ToDo, error) {
+	return r.find(r.db, id)
+}
+
+func (r *GormTodoRepository) Update(todo *models.ToDo) error {
+	if err := checkProject(r.db, todo.ProjectID); err != nil {
+		return err
+	}
+
+	updateFields := map[string]interface{}{
+		"Title":       todo.Title,
+		"Body":        todo.Body,
+		"Status":      todo.Status,
+		"State":       todo.State,
+		"CompletedAt": todo.CompletedAt,
+		"Priority":    todo.Priority,
+		"StartAt":     todo.StartAt,
+		"DueAt":       todo.DueAt,
+		"ProjectID":   todo.ProjectID,
+		"Rank":        todo.Rank,
+		"RRule":       todo.RRule,
+		"SeriesID":    todo.SeriesID,
+		"Version":     gorm.Expr("version + 1"),
+	}
+
+	result := r.db.Model(todo).Where("version = ?", todo.Version).Updates(updateFields)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return r.missingOrConflict(todo.ID)
+	}
+
+	todo.Version++
+
+	return nil
+}
+
+func (r *GormTodoRepository) Delete(id uint, version uint) error {
+	tx := r.db
+	if version > 0 {
+		tx = tx.Where("version = ?", version)
+	}
+
+	result := tx.Delete(&models.ToDo{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return r.missingOrConflict(id)
+	}
+
+	return nil
+}
+
+func (r *GormTodoRepository) FindByIDUnscoped(id uint) (*models.ToDo, error) {
+	return r.find(r.db.Unscoped(), id)
+}
+
+// loadRelations fills in what is read along with todos but stored apart.
+func loadRelations(db *gorm.DB, todos []models.ToDo) error {
+	if err := loadTags(db, todos); err != nil {
+		return err
+	}
+
+	return loadCommentCounts(db, todos)
+}
+
+func (r *GormTodoRepository) find(tx *gorm.DB, id uint) (*models.ToDo, error) {
+	todos := make([]models.ToDo, 1)
+
+	if err := tx.First(&todos[0], id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	if err := loadRelations(r.db, todos); err != nil {
+		return nil, err
+	}
+
+	return &todos[0], nil
+}
+
+func (r *GormTodoRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&models.ToDo{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"DeletedAt": nil,
+			"Version":   gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *GormTodoRepository) Purge(id uint, version uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		purge := tx.Unscoped()
+		if version > 0 {
+			purge = purge.Where("version = ?", version)
+		}
+
+		result := purge.Delete(&models.ToDo{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			if err := tx.Unscoped().First(&models.ToDo{}, id).Error; err != nil {
+				return notFound(err)
+			}
+			return ErrVersionConflict
+		}
+
+		if err := tx.Where("todo_id = ?", id).Delete(&models.Subtask{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("todo_id = ?", id).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("todo_id = ?", id).Delete(&models.Share{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("todo_id = ?", id).Delete(&todoTag{}).Error
+	})
+}
+
+func (r *GormTodoRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	var purged int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&models.ToDo{}).Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+		if err := tx.Where("todo_id IN (?)", expired).Delete(&todoTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("todo_id IN (?)", expired).Delete(&models.Subtask{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("todo_id IN (?)", expired).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("todo_id IN (?)", expired).Delete(&models.Share{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&models.ToDo{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+
+	return purged, err
+}
+
+func (r *GormTodoRepository) AdjacentRank(rank string, next bool, exclude uint) (string, error) {
+	tx := r.db.Unscoped().Model(&models.ToDo{}).Where("id <> ?", exclude)
+	switch {
+	case next:
+		tx = tx.Select("MIN(rank)").Where("rank > ?", rank)
+	case rank != "":
+		tx = tx.Select("MAX(rank)").Where("rank < ?", rank)
+	default:
+		tx = tx.Select("MAX(rank)")
+	}
+
+	var adjacent sql.NullString
+	if err := tx.Scan(&adjacent).Error; err != nil {
+		return "", err
+	}
+
+	return adjacent.String, nil
+}
+
+// missingOrConflict explains why a conditional write touched no rows.
+func (r *GormTodoRepository) missingOrConflict(id uint) error {
+	if _, err := r.FindByID(id); err != nil {
+		return err
+	}
+
+	return ErrVersionConflict
+}
+
+// where applies the filters of query, ignoring its page.
+func (r *GormTodoRepository) where(query TodoQuery) *gorm.DB {
+	tx := r.db.Model(&models.ToDo{})
+	if query.Trashed {
+		tx = tx.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if query.OwnerID != nil {
+		tx = tx.Where("owner_id = ?", *query.OwnerID)
+	}
+	if access := query.Access; access != nil {
+		visible := r.db.Where("owner_id = ?", access.UserID)
+		if len(access.TodoIDs) > 0 {
+			visible = visible.Or("id IN ?", access.TodoIDs)
+		}
+		if len(access.Projects) > 0 {
+			visible = visible.Or("project_id IN ?", access.Projects)
+		}
+		tx = tx.Where(visible)
+	}
+	if query.Status != nil {
+		tx = tx.Where("status = ?", *query.Status)
+	}
+	if len(query.States) > 0 {
+		tx = tx.Where("state IN ?", query.States)
+	}
+	if query.ProjectID != nil {
+		tx = tx.Where("project_id = ?", *query.ProjectID)
+	}
+	if query.SeriesID != nil {
+		tx = tx.Where("series_id = ?", *query.SeriesID)
+	}
+	if len(query.Priorities) > 0 {
+		tx = tx.Where("priority IN ?", query.Priorities)
+	}
+	if len(query.AnyTags) > 0 {
+		tx = tx.Where("id IN (?)", taggedWith(r.db, query.AnyTags, false))
+	}
+	if len(query.AllTags) > 0 {
+		tx = tx.Where("id IN (?)", taggedWith(r.db, query.AllTags, true))
+	}
+	if query.Filter != nil {
+		condition, args := filterCondition(query.Filter)
+		tx = tx.Where(condition, args...)
+	}
+
+	return tx
+}
+
+func reverse(todos []models.ToDo) {
+	for i, j := 0, len(todos)-1; i < j; i, j = i+1, j-1 {
+		todos[i], todos[j] = todos[j], todos[i]
+	}
+}
+
+func paginate(tx *gorm.DB, page Page) *gorm.DB {
+	if page.Offset > 0 {
+		tx = tx.Offset(page.Offset)
+	}
+	if page.Limit > 0 {
+		tx = tx.Limit(page.Limit)
+	}
+
+	return tx
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"example/Studying/models"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// searchConfig is the Postgres text search configuration. "simple" does
+	// no stemming, which keeps it language neutral.
+	searchConfig = "simple"
+	// snippetRadius is how many characters of context the fallback snippet
+	// keeps on each side of the match.
+	snippetRadius = 40
+)
+
+// SearchHit is a todo matching a search together with its relevance and a
+// short HTML-escaped excerpt with the matches wrapped in <mark> tags.
+type SearchHit struct {
+	Todo    models.ToDo
+	Rank    float64
+	Snippet string
+}
+
+// substringHit is the search used by backends without full-text indexing:
+// a case-insensitive substring match. A title match weighs twice a body match
+// and the rank is scaled to 0..1.
+func substringHit(todo models.ToDo, text string) (SearchHit, bool) {
+	needle := strings.ToLower(text)
+	inTitle := strings.Contains(strings.ToLower(todo.Title), needle)
+	inBody := strings.Contains(strings.ToLower(todo.Body), needle)
+	if !inTitle && !inBody {
+		return SearchHit{}, false
+	}
+
+	hit := SearchHit{Todo: todo}
+	if inTitle {
+		hit.Rank += 2.0 / 3
+		hit.Snippet = highlight(todo.Title, needle)
+	}
+	if inBody {
+		hit.Rank += 1.0 / 3
+		hit.Snippet = highlight(todo.Body, needle)
+	}
+
+	return hit, true
+}
+
+// highlight cuts a window of text around the first occurrence of needle
+// (already lower-cased) and marks every occurrence inside it.
+func highlight(text, needle string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Lower-casing changed byte lengths, so offsets would not line up;
+		// fall back to an unmarked excerpt.
+		return html.EscapeString(truncate(text, 2*snippetRadius))
+	}
+
+	at := strings.Index(lower, needle)
+	start, end := at, at+len(needle)
+	for n := 0; n < snippetRadius && start > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+	for n := 0; n < snippetRadius && end < len(text); n++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	window, lowerWindow := text[start:end], lower[start:end]
+	for {
+		i := strings.Index(lowerWindow, needle)
+		if i < 0 {
+			b.WriteString(html.EscapeString(window))
+			break
+		}
+		b.WriteString(html.EscapeString(window[:i]))
+		b.WriteString("<mark>" + html.EscapeString(window[i:i+len(needle)]) + "</mark>")
+		window, lowerWindow = window[i+len(needle):], lowerWindow[i+len(needle):]
+	}
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+func truncate(text string, n int) string {
+	for i := range text {
+		if n == 0 {
+			return text[:i] + "…"
+		}
+		n--
+	}
+
+	return text
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"example/Studying/models"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSort   = errors.New("Invalid sort field")
+	ErrInvalidCursor = errors.New("Invalid cursor")
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Cursor pins a row inside a sorted listing by the values of its sort fields
+// (id last), so the next page starts right after it even if rows were added
+// or removed meanwhile.
+type Cursor []json.RawMessage
+
+func IsSortable(field string) bool {
+	return todoFields[field].sortable
+}
+
+// CursorFor captures the position of todo in a listing ordered by sort.
+func CursorFor(todo models.ToDo, sort []SortField) (Cursor, error) {
+	_, keys, err := orderKeys(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := make(Cursor, len(keys))
+	for i, value := range valuesOf(todo, keys) {
+		if cursor[i], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return cursor, nil
+}
+
+// orderKeys resolves sort against the whitelist, or to smartKeys when it is
+// empty, and appends id as the final tie breaker so every row has a unique
+// position.
+func orderKeys(sort []SortField) ([]SortField, []todoField, error) {
+	fields := make([]SortField, 0, len(sort)+len(smartKeys)+1)
+	keys := make([]todoField, 0, len(sort)+len(smartKeys)+1)
+	if len(sort) == 0 {
+		for _, key := range smartKeys {
+			fields = append(fields, key.SortField)
+			keys = append(keys, key.todoField)
+		}
+	}
+	for _, field := range sort {
+		key, ok := todoFields[field.Field]
+		if !ok || !key.sortable {
+			return nil, nil, fmt.Errorf("%w %q", ErrInvalidSort, field.Field)
+		}
+		if field.Field == "id" {
+			return append(fields, field), append(keys, key), nil
+		}
+		if key.nullable() {
+			missing, date := key.nullsLast()
+			fields = append(fields, SortField{Field: field.Field + "_missing"}, field)
+			keys = append(keys, missing, date)
+			continue
+		}
+		fields = append(fields, field)
+		keys = append(keys, key)
+	}
+
+	return append(fields, SortField{Field: "id"}), append(keys, todoFields["id"]), nil
+}
+
+// cursorValues decodes the values stored in cursor into the Go types the
+// sort fields have on models.ToDo.
+func cursorValues(cursor Cursor, keys []todoField) ([]interface{}, error) {
+	if len(cursor) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		ptr := reflect.New(reflect.TypeOf(key.value(models.ToDo{})))
+		if err := json.Unmarshal(cursor[i], ptr.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = ptr.Elem().Interface()
+	}
+
+	return values, nil
+}
+
+func valuesOf(todo models.ToDo, keys []todoField) []interface{} {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = key.value(todo)
+	}
+
+	return values
+}
+
+// keysetCondition builds the row-value comparison "sort key after cursor"
+// (or before, when backward) as a chain of OR'ed prefixes, which every SQL
+// dialect understands regardless of mixed sort directions.
+func keysetCondition(cursor Cursor, fields []SortField, keys []todoField, backward bool) (string, []interface{}, error) {
+	values, err := cursorValues(cursor, keys)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	for i := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].column+" = ?")
+			args = append(args, values[j])
+		}
+
+		op := ">"
+		if fields[i].Desc != backward {
+			op = "<"
+		}
+		parts = append(parts, keys[i].column+" "+op+" ?")
+		args = append(args, values[i])
+
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return strings.Join(clauses, " OR "), args, nil
+}
+
+// compareToValues orders todo against the sort values of another row,
+// honouring each field's direction.
+func compareToValues(todo models.ToDo, values []interface{}, fields []SortField, keys []todoField) int {
+	for i, key := range keys {
+		if c := compareValues(key.value(todo), values[i]); c != 0 {
+			if fields[i].Desc {
+				return -c
+			}
+			return c
+		}
+	}
+
+	return 0
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case uint:
+		return compareOrdered(a, b.(uint))
+	case models.Priority:
+		return compareOrdered(uint(a), uint(b.(models.Priority)))
+	case string:
+		return compareOrdered(a, b.(string))
+	case bool:
+		return compareOrdered(boolRank(a), boolRank(b.(bool)))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+
+	panic(fmt.Sprintf("repositories: cannot compare %T", a))
+}
+
+func compareOrdered[T uint | int | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
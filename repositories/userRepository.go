@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"errors"
+	"example/Studying/models"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDuplicateUser means the username or the email is already taken.
+var ErrDuplicateUser = errors.New("user already exists")
+
+// ErrLastAdmin means the change would leave nobody with the admin role.
+var ErrLastAdmin = errors.New("There has to be at least one admin")
+
+// UserRepository stores accounts, their login sessions and their API keys.
+type UserRepository interface {
+	// Create fails with ErrDuplicateUser if the username or email is taken.
+	Create(user *models.User) error
+	// Register creates user like Create, but as an admin when there is no
+	// admin yet, who then owns the rows from before there were users. Two
+	// users registering at once can't both become admins.
+	Register(user *models.User) error
+	FindByID(id uint) (*models.User, error)
+	// FindByLogin finds the user whose username or email is login.
+	FindByLogin(login string) (*models.User, error)
+	// List returns every user in the order they registered.
+	List() ([]models.User, error)
+	// SetRole gives the user with id a new role. It fails with ErrLastAdmin
+	// rather than take the role away from the only admin, even when two
+	// admins step down at once.
+	SetRole(id uint, role models.Role) error
+
+	CreateSession(session *models.Session) error
+	// FindSession returns the session with tokenHash unless it was revoked
+	// or expired before now.
+	FindSession(tokenHash string, now time.Time) (*models.Session, error)
+	// RotateSession gives session a new token hash and expiry, provided its
+	// stored hash is still session.TokenHash; otherwise, for instance when
+	// the old token was used twice at once, it fails with ErrNotFound.
+	RotateSession(session *models.Session, tokenHash string, expiresAt time.Time) error
+	// RevokeSession ends the session with id. Revoking it again is a no-op.
+	RevokeSession(id uint, now time.Time) error
+
+	CreateAPIKey(key *models.APIKey) error
+	// ListAPIKeys returns the keys of a user, newest first.
+	ListAPIKeys(userID uint) ([]models.APIKey, error)
+	// FindAPIKey returns the key with keyHash unless it expired before now.
+	FindAPIKey(keyHash string, now time.Time) (*models.APIKey, error)
+	// TouchAPIKey sets when the key with id was last used.
+	TouchAPIKey(id uint, now time.Time) error
+	// DeleteAPIKey deletes a key of the user with userID.
+	DeleteAPIKey(userID uint, id uint) error
+}
+
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
+}
+
+func (r *GormUserRepository) Create(user *models.User) error {
+	return translate(r.db, r.db.Create(user).Error, ErrDuplicateUser)
+}
+
+func (r *GormUserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &user, nil
+}
+
+func (r *GormUserRepository) FindByLogin(login string) (*models.User, error) {
+	var user models.User
+
+	if err := r.db.Where("username = ? OR email = ?", login, login).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	return &user, nil
+}
+
+func (r *GormUserRepository) List() ([]models.User, error) {
+	users := []models.User{}
+	if err := r.db.Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *GormUserRepository) Register(user *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The admin that would make the difference doesn't exist yet, so
+		// there is no row to lock: registrations wait for each other on the
+		// table instead. SQLite allows a single writer anyway.
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+				return err
+			}
+		}
+
+		var admins int64
+		if err := tx.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			return translate(tx, tx.Create(user).Error, ErrDuplicateUser)
+		}
+
+		user.Role = models.RoleAdmin
+		if err := translate(tx, tx.Create(user).Error, ErrDuplicateUser); err != nil {
+			return err
+		}
+
+		// Rows from before there were users wait for the first admin when
+		// the database was migrated without one.
+		return ClaimOwnerless(tx, user.ID)
+	})
+}
+
+func (r *GormUserRepository) SetRole(id uint, role models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the admins makes a concurrent demotion wait and then see
+		// this one.
+		var admins []uint
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&models.User{}).
+			Where("role = ?", models.RoleAdmin).Pluck("id", &admins).Error
+		if err != nil {
+			return err
+		}
+		if role != models.RoleAdmin && len(admins) <= 1 && slices.Contains(admins, id) {
+			return ErrLastAdmin
+		}
+
+		result := tx.Model(&models.User{}).Where("id = ?", id).Update("role", role)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (r *GormUserRepository) CreateSession(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *GormUserRepository) FindSession(tokenHash string, now time.Time) (*models.Session, error) {
+	var session models.Session
+
+	err := r.db.Where("token_hash = ? AND expires_at > ? AND revoked_at IS NULL", tokenHash, now).First(&session).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &session, nil
+}
+
+func (r *GormUserRepository) RotateSession(session *models.Session, tokenHash string, expiresAt time.Time) error {
+	result := r.db.Model(session).Where("token_hash = ? AND revoked_at IS NULL", session.TokenHash).
+		Updates(map[string]interface{}{"TokenHash": tokenHash, "ExpiresAt": expiresAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	session.TokenHash, session.ExpiresAt = tokenHash, expiresAt
+	return nil
+}
+
+func (r *GormUserRepository) RevokeSession(id uint, now time.Time) error {
+	return r.db.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", now).Error
+}
+
+func (r *GormUserRepository) CreateAPIKey(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *GormUserRepository) ListAPIKeys(userID uint) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *GormUserRepository) FindAPIKey(keyHash string, now time.Time) (*models.APIKey, error) {
+	var key models.APIKey
+
+	err := r.db.Where("key_hash = ? AND (expires_at IS NULL OR expires_at > ?)", keyHash, now).First(&key).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &key, nil
+}
+
+func (r *GormUserRepository) TouchAPIKey(id uint, now time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", now).Error
+}
+
+func (r *GormUserRepository) DeleteAPIKey(userID uint, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.APIKey{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
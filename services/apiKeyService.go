@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// APIKeyPrefix starts every API key, which is how they are told apart
+	// from access tokens in the Authorization header.
+	APIKeyPrefix = "tdk_"
+	// apiKeyShownLength is how much of a key is kept in APIKey.Prefix.
+	apiKeyShownLength = 12
+	// apiKeyTouchInterval limits how often using a key writes LastUsedAt.
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("Invalid API key")
+	// ErrAPIKeyForbidden means an API key was used for something only a
+	// logged in user may do, such as managing API keys.
+	ErrAPIKeyForbidden = errors.New("API keys can't be used for this, log in instead")
+)
+
+// Identity is who a request is made by. APIKey is set if the caller used an
+// API key rather than an access token; its scopes limit what it may do.
+type Identity struct {
+	User   *models.User
+	APIKey *models.APIKey
+}
+
+// CreateAPIKey creates a key for user with scopes (read, write), which stops
+// working at expiresAt unless that is nil. The returned key is the only
+// copy there is.
+func (s *AuthService) CreateAPIKey(user *models.User, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	key := &models.APIKey{UserID: user.ID, Name: strings.TrimSpace(name)}
+	if n := utf8.RuneCountInString(key.Name); n < 1 || n > 100 {
+		return nil, "", &ValidationError{Field: "name", Message: "Name have to be from 1 to 100 letters"}
+	}
+
+	for _, name := range scopes {
+		scope, ok := models.ParseScope(name)
+		if !ok {
+			return nil, "", &ValidationError{Field: "scopes", Message: "Scopes have to be read or write"}
+		}
+		if !slices.Contains(key.Scopes, scope) {
+			key.Scopes = append(key.Scopes, scope)
+		}
+	}
+	if len(key.Scopes) == 0 {
+		return nil, "", &ValidationError{Field: "scopes", Message: "API key needs at least one scope"}
+	}
+
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return nil, "", &ValidationError{Field: "expires_at", Message: "Expiry have to be in the future"}
+		}
+		expires := expiresAt.UTC()
+		key.ExpiresAt = &expires
+	}
+
+	random, err := newToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret := APIKeyPrefix + random
+	key.Prefix = secret[:apiKeyShownLength]
+	key.KeyHash = hashToken(secret)
+
+	if err := s.users.CreateAPIKey(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// ListAPIKeys returns the keys of user, expired ones included, newest first.
+func (s *AuthService) ListAPIKeys(user *models.User) ([]models.APIKey, error) {
+	return s.users.ListAPIKeys(user.ID)
+}
+
+// RevokeAPIKey deletes a key of user; it stops working right away.
+func (s *AuthService) RevokeAPIKey(user *models.User, keyID string) error {
+	id, err := parseID(keyID)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	return s.users.DeleteAPIKey(user.ID, id)
+}
+
+// authenticateKey finds the user an API key belongs to and notes that the
+// key was used.
+func (s *AuthService) authenticateKey(secret string) (*Identity, error) {
+	now := time.Now()
+	key, err := s.users.FindAPIKey(hashToken(secret), now)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByID(key.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.users.TouchAPIKey(key.ID, now.UTC()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Identity{User: user, APIKey: key}, nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/storage"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultAttachmentMaxSize is the largest file accepted unless configured
+// otherwise.
+const DefaultAttachmentMaxSize = 10 << 20
+
+// sniffLength is how much of a file http.DetectContentType looks at.
+const sniffLength = 512
+
+// ErrAttachmentTooLarge means a file is over the size limit.
+var ErrAttachmentTooLarge = errors.New("Attachment is too large")
+
+// AttachmentService keeps the metadata of attachments in a repository and
+// their content in a blob store.
+type AttachmentService struct {
+	attachments repositories.AttachmentRepository
+	store       storage.BlobStore
+	maxSize     int64
+}
+
+func NewAttachmentService(attachments repositories.AttachmentRepository, store storage.BlobStore, maxSize int64) *AttachmentService {
+	return &AttachmentService{attachments: attachments, store: store, maxSize: maxSize}
+}
+
+// MaxSize is the largest file Upload accepts, in bytes.
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+func (s *AttachmentService) ListAttachments(todo *models.ToDo) ([]models.Attachment, error) {
+	return s.attachments.List(todo.ID)
+}
+
+func (s *AttachmentService) FindAttachment(todo *models.ToDo, attachmentID string) (*models.Attachment, error) {
+	id, err := parseID(attachmentID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return s.attachments.FindByID(todo.ID, id)
+}
+
+// Upload stores size bytes of content as a file called filename attached to
+// todo. The content type is sniffed from the content itself, falling back
+// to the file extension for content that could be anything.
+func (s *AttachmentService) Upload(todo *models.ToDo, filename string, content io.Reader, size int64) (*models.Attachment, error) {
+	if size > s.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	name := cleanFilename(filename)
+	if n := utf8.RuneCountInString(name); n == 0 || n > 255 {
+		return nil, &ValidationError{Field: "file", Message: "File name have to be 1 to 255 letters"}
+	}
+
+	head := make([]byte, min(size, sniffLength))
+	if _, err := io.ReadFull(content, head); err != nil {
+		return nil, storage.ErrSizeMismatch
+	}
+
+	key, err := newBlobKey(todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		TodoID:      todo.ID,
+		Filename:    name,
+		ContentType: sniffContentType(head, name),
+		Size:        size,
+		Key:         key,
+	}
+
+	if err := s.store.Put(key, io.MultiReader(bytes.NewReader(head), content), size, attachment.ContentType); err != nil {
+		return nil, err
+	}
+	if err := s.attachments.Create(attachment); err != nil {
+		s.store.Delete(key)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open streams the content of attachment; the caller closes it.
+func (s *AttachmentService) Open(attachment *models.Attachment) (io.ReadCloser, error) {
+	content, err := s.store.Get(attachment.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+
+	return content, err
+}
+
+// DeleteAttachment removes the content before the metadata, so a failure
+// leaves an attachment that can be deleted again rather than a lost blob.
+func (s *AttachmentService) DeleteAttachment(attachment *models.Attachment) error {
+	if err := s.store.Delete(attachment.Key); err != nil {
+		return err
+	}
+
+	return s.attachments.Delete(attachment.TodoID, attachment.ID)
+}
+
+// RemoveOrphans deletes the attachments of purged todos and reports how many
+// were removed. Whatever fails is left for the next run.
+func (s *AttachmentService) RemoveOrphans() (int, error) {
+	orphans, err := s.attachments.Orphans()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := range orphans {
+		if err := s.DeleteAttachment(&orphans[i]); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// cleanFilename keeps the last path element of a client supplied name,
+// without control characters.
+func cleanFilename(filename string) string {
+	filename = filename[strings.LastIndexAny(filename, `/\`)+1:]
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filename)
+
+	return strings.TrimSpace(filename)
+}
+
+func sniffContentType(head []byte, filename string) string {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" {
+		if byExtension := mime.TypeByExtension(filepath.Ext(filename)); byExtension != "" {
+			return byExtension
+		}
+	}
+
+	return contentType
+}
+
+func newBlobKey(todoID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("todos/%d/%s", todoID, hex.EncodeToString(random)), nil
+}
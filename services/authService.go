@@ -0,0 +1,289 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"example/Studying/jwt"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// DefaultAccessTTL is how long an access token lasts unless
+	// ACCESS_TOKEN_TTL says otherwise. Access tokens are checked without
+	// touching the database, so they can't be revoked and are kept short.
+	DefaultAccessTTL = 15 * time.Minute
+	// DefaultRefreshTTL is how long a session lasts without being refreshed
+	// unless REFRESH_TOKEN_TTL says otherwise.
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes, so longer passwords would
+	// silently match on their prefix.
+	maxPasswordBytes = 72
+)
+
+var (
+	ErrDuplicateUser = repositories.ErrDuplicateUser
+	// ErrInvalidCredentials is returned for an unknown login and a wrong
+	// password alike, so that logins can't be used to probe for accounts.
+	ErrInvalidCredentials = errors.New("Invalid login or password")
+	ErrUnauthenticated    = errors.New("Authentication required")
+	ErrInvalidToken       = errors.New("Invalid access token")
+	ErrTokenExpired       = errors.New("Access token expired")
+	// ErrInvalidRefreshToken means the refresh token is unknown, was already
+	// used, or its session expired or was revoked.
+	ErrInvalidRefreshToken = errors.New("Invalid refresh token")
+)
+
+// dummyHash is compared against when a login names nobody, so that unknown
+// and known logins take as long to reject.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+// AuthConfig says how access tokens are signed and checked and how long
+// tokens last.
+type AuthConfig struct {
+	Signer     *jwt.Signer
+	Verifier   *jwt.Verifier
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// AuthService registers users, logs them in and tells who an access token
+// belongs to. A login starts a session: a short-lived signed access token
+// for API calls and a refresh token that trades itself for new ones.
+type AuthService struct {
+	users  repositories.UserRepository
+	config AuthConfig
+}
+
+func NewAuthService(users repositories.UserRepository, config AuthConfig) *AuthService {
+	return &AuthService{users: users, config: config}
+}
+
+// Register creates an account. Username and email are lower-cased; both
+// have to be unused. The account is an editor, or an admin while there is
+// no admin yet, so the first user can hand out roles.
+func (s *AuthService) Register(username string, email string, password string) (*models.User, error) {
+	user := &models.User{
+		Username: strings.ToLower(strings.TrimSpace(username)),
+		Email:    strings.ToLower(strings.TrimSpace(email)),
+	}
+	if err := validateUser(user, password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = string(hash)
+
+	user.Role = models.RoleEditor
+	if err := s.users.Register(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Tokens is what a login or a refresh hands to the client. RefreshToken is
+// the only copy there is.
+type Tokens struct {
+	User             *models.User
+	AccessToken      string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Login checks the password of the user whose username or email is login
+// and starts a session.
+func (s *AuthService) Login(login string, password string) (*Tokens, error) {
+	user, err := s.users.FindByLogin(strings.ToLower(strings.TrimSpace(login)))
+	if errors.Is(err, ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	refresh, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:    user.ID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: now.Add(s.config.RefreshTTL).UTC(),
+	}
+	if err := s.users.CreateSession(session); err != nil {
+		return nil, err
+	}
+
+	return s.issue(user, session, refresh, now)
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh
+// token; the old refresh token stops working.
+func (s *AuthService) Refresh(refreshToken string) (*Tokens, error) {
+	now := time.Now()
+	session, err := s.users.FindSession(hashToken(refreshToken), now)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByID(session.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	err = s.users.RotateSession(session, hashToken(refresh), now.Add(s.config.RefreshTTL).UTC())
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(user, session, refresh, now)
+}
+
+// Logout revokes the session of a refresh token. Access tokens already
+// issued for it stay valid until they expire.
+func (s *AuthService) Logout(refreshToken string) error {
+	now := time.Now()
+	session, err := s.users.FindSession(hashToken(refreshToken), now)
+	if errors.Is(err, ErrNotFound) {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.users.RevokeSession(session.ID, now)
+}
+
+// Authenticate tells who a bearer token, an access token or an API key,
+// belongs to. For an access token the user is read from the token alone and
+// only has its ID, Username and Role set, so a new role takes effect with the
+// next refresh.
+func (s *AuthService) Authenticate(token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		return s.authenticateKey(token)
+	}
+
+	claims, err := s.config.Verifier.Verify(token, time.Now())
+	if errors.Is(err, jwt.ErrExpired) {
+		return nil, ErrTokenExpired
+	}
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || id == 0 {
+		return nil, ErrInvalidToken
+	}
+	// Tokens issued before roles existed have none; refreshing gets one.
+	role, ok := models.ParseRole(claims.Role)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return &Identity{User: &models.User{ID: uint(id), Username: claims.Username, Role: role}}, nil
+}
+
+func (s *AuthService) issue(user *models.User, session *models.Session, refresh string, now time.Time) (*Tokens, error) {
+	expires := time.Unix(now.Add(s.config.AccessTTL).Unix(), 0).UTC()
+	access, err := s.config.Signer.Sign(jwt.Claims{
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		Username:  user.Username,
+		Role:      string(user.Role),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expires.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		User:             user,
+		AccessToken:      access,
+		ExpiresAt:        expires,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func validateUser(user *models.User, password string) error {
+	if n := utf8.RuneCountInString(user.Username); n < 3 || n > 50 {
+		return &ValidationError{Field: "username", Message: "Username have to be from 3 to 50 letters"}
+	}
+	for _, r := range user.Username {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("._-", r)) {
+			return &ValidationError{Field: "username", Message: "Username can only have latin letters, digits, dots, dashes and underscores"}
+		}
+	}
+
+	address, err := mail.ParseAddress(user.Email)
+	if err != nil || address.Address != user.Email || len(user.Email) > 255 {
+		return &ValidationError{Field: "email", Message: "Email is not valid"}
+	}
+
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return &ValidationError{Field: "password", Message: "Password have to be at least 8 characters"}
+	}
+	if len(password) > maxPasswordBytes {
+		return &ValidationError{Field: "password", Message: "Password have to be at most 72 bytes"}
+	}
+
+	return nil
+}
+
+// newToken returns 256 random bits, URL-safe encoded.
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
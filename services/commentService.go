@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxCommentLength = 10000
+
+// ErrNotCommentAuthor means somebody other than the author of a comment or
+// an owner of its todo tried to change it.
+var ErrNotCommentAuthor = errors.New("Only the author of a comment or an owner of the todo can change it")
+
+// CommentService manages the discussion thread of a todo for one user.
+type CommentService struct {
+	comments repositories.CommentRepository
+	todos    repositories.TodoRepository
+	userID   uint
+}
+
+// NewCommentService creates a service acting for the user with userID. todos
+// tells the roles of the user; give it the repository owned by the user.
+func NewCommentService(comments repositories.CommentRepository, todos repositories.TodoRepository, userID uint) *CommentService {
+	return &CommentService{comments: comments, todos: todos, userID: userID}
+}
+
+// CommentPage is a window of a thread, oldest comment first.
+type CommentPage struct {
+	Comments []models.Comment
+	Total    int64
+	Limit    int
+	Offset   int
+}
+
+// ListComments pages through the comments of todo with limit and offset;
+// threads have no cursors or sort orders.
+func (s *CommentService) ListComments(todo *models.ToDo, req PageRequest) (*CommentPage, error) {
+	if req.Limit < 0 || req.Offset < 0 || req.Cursor != "" || req.Sort != "" {
+		return nil, ErrInvalidPage
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	comments, err := s.comments.List(todo.ID, repositories.Page{Limit: limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.comments.Count(todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CommentPage{Comments: comments, Total: total, Limit: limit, Offset: req.Offset}, nil
+}
+
+func (s *CommentService) FindComment(todo *models.ToDo, commentID string) (*models.Comment, error) {
+	id, err := parseID(commentID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return s.comments.FindByID(todo.ID, id)
+}
+
+// CreateComment adds a comment by the user, shown under the name author.
+func (s *CommentService) CreateComment(todo *models.ToDo, author string, body string) (*models.Comment, error) {
+	comment := &models.Comment{TodoID: todo.ID, AuthorID: s.userID, Author: strings.TrimSpace(author), Body: body}
+	if err := validateComment(comment); err != nil {
+		return nil, err
+	}
+
+	if err := s.comments.Create(comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// EditComment replaces the body of comment on todo; the author stays. Only
+// the author and the owners of todo may edit it. comment is left untouched if
+// validation fails.
+func (s *CommentService) EditComment(todo *models.ToDo, comment *models.Comment, body string) error {
+	if err := s.requireAuthor(todo, comment); err != nil {
+		return err
+	}
+
+	updated := *comment
+	updated.Body = body
+
+	if err := validateComment(&updated); err != nil {
+		return err
+	}
+
+	if err := s.comments.Update(&updated); err != nil {
+		return err
+	}
+
+	*comment = updated
+
+	return nil
+}
+
+// DeleteComment deletes comment on todo. Only the author and the owners of
+// todo may delete it.
+func (s *CommentService) DeleteComment(todo *models.ToDo, comment *models.Comment) error {
+	if err := s.requireAuthor(todo, comment); err != nil {
+		return err
+	}
+
+	return s.comments.Delete(comment.TodoID, comment.ID)
+}
+
+// requireAuthor fails with ErrNotCommentAuthor unless the user wrote comment
+// or has the owner role on todo, directly or through a share.
+func (s *CommentService) requireAuthor(todo *models.ToDo, comment *models.Comment) error {
+	if comment.AuthorID == s.userID {
+		return nil
+	}
+
+	role, err := roleOf(s.todos, todo)
+	if err != nil {
+		return err
+	}
+	if !role.Includes(models.ShareOwner) {
+		return ErrNotCommentAuthor
+	}
+
+	return nil
+}
+
+func validateComment(comment *models.Comment) error {
+	if n := utf8.RuneCountInString(comment.Author); n == 0 || n > 100 {
+		return &ValidationError{Field: "author", Message: "Author have to be 1 to 100 letters"}
+	}
+	if strings.TrimSpace(comment.Body) == "" {
+		return &ValidationError{Field: "body", Message: "Comment body is required"}
+	}
+	if utf8.RuneCountInString(comment.Body) > maxCommentLength {
+		return &ValidationError{Field: "body", Message: "Comment body have to be at most 10000 letters"}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/filters"
+	"example/Studying/models"
+	"time"
+)
+
+var (
+	ErrInvalidDue      = errors.New("Wrong due filter, use overdue, today or week")
+	ErrInvalidDate     = errors.New(`Wrong date format, use "2024-01-31" or RFC 3339 time`)
+	ErrInvalidTimezone = errors.New("Unknown time zone")
+)
+
+// dateLayouts are accepted for due_before and due_after, most specific first.
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// DueFilter selects todos by due date. Window is "overdue" (due in the past
+// and neither done nor cancelled), "today" or "week" (the current
+// Monday-based week). After is inclusive and Before exclusive. Days and
+// dates without an offset are taken in TZ, an IANA zone name defaulting to
+// UTC.
+type DueFilter struct {
+	Window string
+	Before string
+	After  string
+	TZ     string
+}
+
+// condition translates f into a filter over due_at, or nil if f is empty.
+func (f DueFilter) condition(now time.Time) (filters.Node, error) {
+	loc := time.UTC
+	if f.TZ != "" {
+		var err error
+		if loc, err = time.LoadLocation(f.TZ); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+	}
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var node filters.Node
+	switch f.Window {
+	case "":
+	case "overdue":
+		node = and(dueAt(filters.Less, now), open())
+	case "today":
+		node = dueWithin(today, today.AddDate(0, 0, 1))
+	case "week":
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		node = dueWithin(monday, monday.AddDate(0, 0, 7))
+	default:
+		return nil, ErrInvalidDue
+	}
+
+	if f.After != "" {
+		after, err := parseDate(f.After, loc)
+		if err != nil {
+			return nil, err
+		}
+		node = and(node, dueAt(filters.GreatEq, after))
+	}
+	if f.Before != "" {
+		before, err := parseDate(f.Before, loc)
+		if err != nil {
+			return nil, err
+		}
+		node = and(node, dueAt(filters.Less, before))
+	}
+
+	return node, nil
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, ErrInvalidDate
+}
+
+func dueAt(op filters.Op, t time.Time) filters.Node {
+	return filters.Comparison{Field: "due_at", Op: op, Value: t}
+}
+
+// open matches todos that are neither done nor cancelled.
+func open() filters.Node {
+	return and(
+		filters.Comparison{Field: "state", Op: filters.NotEq, Value: string(models.StateDone)},
+		filters.Comparison{Field: "state", Op: filters.NotEq, Value: string(models.StateCancelled)},
+	)
+}
+
+func dueWithin(from, to time.Time) filters.Node {
+	return and(dueAt(filters.GreatEq, from), dueAt(filters.Less, to))
+}
+
+// and joins conditions, skipping nil ones.
+func and(left, right filters.Node) filters.Node {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+
+	return filters.And{Left: left, Right: right}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/ranking"
+	"fmt"
+)
+
+// MoveTodo changes the place of todo in the manual order (sort=rank): right
+// after the todo with id after, right before the one with id before, or
+// between the two when both are given. Only the rank of todo is rewritten,
+// the other todos keep theirs.
+func (s *TodoService) MoveTodo(todo *models.ToDo, after *uint, before *uint) error {
+	if after == nil && before == nil {
+		return &ValidationError{Field: "after", Message: "Either after or before is required"}
+	}
+
+	var low, high string
+	if after != nil {
+		neighbour, err := s.neighbour(todo, *after, "after")
+		if err != nil {
+			return err
+		}
+		low = neighbour.Rank
+	}
+	if before != nil {
+		neighbour, err := s.neighbour(todo, *before, "before")
+		if err != nil {
+			return err
+		}
+		high = neighbour.Rank
+	}
+
+	var err error
+	switch {
+	case before == nil:
+		high, err = s.repo.AdjacentRank(low, true, todo.ID)
+	case after == nil:
+		low, err = s.repo.AdjacentRank(high, false, todo.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	rank, err := ranking.Between(low, high)
+	if errors.Is(err, ranking.ErrInvalidKey) {
+		return &ValidationError{Field: "before", Message: "Todo after have to come before todo before"}
+	}
+	if err != nil {
+		return err
+	}
+
+	updated := *todo
+	updated.Rank = rank
+	if err := s.repo.Update(&updated); err != nil {
+		return err
+	}
+
+	*todo = updated
+
+	return nil
+}
+
+// neighbour finds the todo another one is moved next to.
+func (s *TodoService) neighbour(todo *models.ToDo, id uint, field string) (*models.ToDo, error) {
+	if id == todo.ID {
+		return nil, &ValidationError{Field: field, Message: "Todo can't be moved next to itself"}
+	}
+
+	neighbour, err := s.repo.FindByID(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, &ValidationError{Field: field, Message: fmt.Sprintf("There is no todo with id %d", id)}
+	}
+
+	return neighbour, err
+}
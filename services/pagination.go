@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = repositories.ErrInvalidCursor
+	ErrInvalidSort   = repositories.ErrInvalidSort
+	ErrInvalidPage   = errors.New("Wrong pagination params")
+)
+
+// PageRequest is what a client asks for: either an offset window or an opaque
+// cursor taken from a previous TodoPage, over a listing ordered by Sort (see
+// ParseSort). A cursor is only valid with the sort it was issued for.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   string
+}
+
+type TodoPage struct {
+	Todos      []models.ToDo
+	Total      int64
+	Limit      int
+	Offset     int
+	NextCursor string
+	PrevCursor string
+}
+
+type cursor struct {
+	Sort   string              `json:"sort,omitempty"`
+	After  repositories.Cursor `json:"after,omitempty"`
+	Before repositories.Cursor `json:"before,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if (c.After == nil) == (c.Before == nil) {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// listPage runs query one row past the requested limit so it can tell
+// whether another page follows without a second round trip.
+func (s *TodoService) listPage(query repositories.TodoQuery, req PageRequest) (*TodoPage, error) {
+	if req.Limit < 0 || req.Offset < 0 || (req.Cursor != "" && req.Offset > 0) {
+		return nil, ErrInvalidPage
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	sort, err := ParseSort(req.Sort)
+	if err != nil {
+		return nil, err
+	}
+	query.Sort = sort
+	query.Page = repositories.Page{Limit: limit + 1, Offset: req.Offset}
+
+	var c cursor
+	if req.Cursor != "" {
+		if c, err = decodeCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+		if c.Sort != formatSort(sort) {
+			return nil, ErrInvalidCursor
+		}
+		query.Page.After = c.After
+		query.Page.Before = c.Before
+	}
+
+	todos, err := s.repo.List(query)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.Count(query)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(todos) > limit
+	if more {
+		if c.Before != nil {
+			todos = todos[1:]
+		} else {
+			todos = todos[:limit]
+		}
+	}
+
+	page := &TodoPage{
+		Todos:  todos,
+		Total:  total,
+		Limit:  limit,
+		Offset: req.Offset,
+	}
+
+	if len(todos) == 0 {
+		return page, nil
+	}
+
+	hasNext := more || c.Before != nil
+	hasPrev := req.Offset > 0 || c.After != nil || (more && c.Before != nil)
+
+	if hasNext {
+		after, err := repositories.CursorFor(todos[len(todos)-1], sort)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = encodeCursor(cursor{Sort: formatSort(sort), After: after})
+	}
+	if hasPrev {
+		before, err := repositories.CursorFor(todos[0], sort)
+		if err != nil {
+			return nil, err
+		}
+		page.PrevCursor = encodeCursor(cursor{Sort: formatSort(sort), Before: before})
+	}
+
+	return page, nil
+}
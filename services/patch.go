@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"example/Studying/models"
+	"example/Studying/recurrence"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	ErrUnsupportedPatch = errors.New("Unsupported patch content type")
+	ErrInvalidPatch     = errors.New("Wrong patch format")
+	ErrPatchConflict    = errors.New("Patch test operation failed")
+)
+
+// TodoFields is the client-editable part of a todo. PUT bodies and the
+// documents patches apply to have exactly this shape.
+// A nil ProjectID puts the todo in the inbox. State and Status are resolved
+// against the current state by the workflow, see Workflow.
+type TodoFields struct {
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Status    bool       `json:"status"`
+	State     string     `json:"state"`
+	Priority  string     `json:"priority"`
+	StartAt   *time.Time `json:"start_at"`
+	DueAt     *time.Time `json:"due_at"`
+	ProjectID *uint      `json:"project_id"`
+	RRule     string     `json:"rrule"`
+}
+
+func fieldsOf(todo *models.ToDo) TodoFields {
+	return TodoFields{
+		Title:     todo.Title,
+		Body:      todo.Body,
+		Status:    todo.Status,
+		State:     string(todo.State),
+		Priority:  todo.Priority.String(),
+		StartAt:   todo.StartAt,
+		DueAt:     todo.DueAt,
+		ProjectID: todo.ProjectID,
+		RRule:     todo.RRule,
+	}
+}
+
+// applyTo copies f onto todo, except for the state, which only a Workflow
+// changes. f must have passed validateFields.
+func (f TodoFields) applyTo(todo *models.ToDo) {
+	todo.Title = f.Title
+	todo.Body = f.Body
+	todo.Priority, _ = models.ParsePriority(f.Priority)
+	todo.StartAt = utc(f.StartAt)
+	todo.DueAt = utc(f.DueAt)
+	todo.ProjectID = f.ProjectID
+	todo.RRule = ""
+	if rule, err := recurrence.Parse(f.RRule); err == nil {
+		todo.RRule = rule.String()
+	}
+}
+
+// utc normalises an optional instant so every backend stores and compares
+// the same representation.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
+
+// PatchTodo applies an RFC 7396 merge patch or an RFC 6902 JSON patch,
+// depending on contentType, to the editable fields of todo. Fields the patch
+// does not mention keep their current values.
+func (s *TodoService) PatchTodo(todo *models.ToDo, contentType string, patch []byte) error {
+	_, err := s.PatchTodoScoped(todo, contentType, patch, EditThis)
+	return err
+}
+
+// PatchTodoScoped is PatchTodo for recurring todos, see ReplaceTodoScoped.
+func (s *TodoService) PatchTodoScoped(todo *models.ToDo, contentType string, patch []byte, scope EditScope) (*models.ToDo, error) {
+	doc, err := json.Marshal(fieldsOf(todo))
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch contentType {
+	case MergePatchType, "application/json":
+		patched, err = jsonpatch.MergePatch(doc, patch)
+	case JSONPatchType:
+		var ops jsonpatch.Patch
+		if ops, err = jsonpatch.DecodePatch(patch); err != nil {
+			return nil, ErrInvalidPatch
+		}
+		patched, err = ops.Apply(doc)
+	default:
+		return nil, ErrUnsupportedPatch
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return nil, ErrPatchConflict
+	}
+	if err != nil {
+		return nil, ErrInvalidPatch
+	}
+
+	// Decoding strictly rejects patches that add unknown members or change a
+	// field's type, e.g. {"status": null} or {"id": 7}.
+	var fields TodoFields
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	if err := requireFields(patched); err != nil {
+		return nil, err
+	}
+
+	return s.ReplaceTodoScoped(todo, fields, scope)
+}
+
+// requireFields checks that doc still has every editable member, so removing
+// one through a patch is an error instead of a silent reset.
+func requireFields(doc []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &members); err != nil {
+		return ErrInvalidPatch
+	}
+
+	for _, name := range []string{"title", "body", "status"} {
+		if value, ok := members[name]; !ok || string(value) == "null" {
+			return &ValidationError{Field: name, Message: "Field " + name + " is required"}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrProjectNotFound means a todo refers to a project that does not exist
+// or that the user can't file todos into.
+var ErrProjectNotFound = repositories.ErrProjectNotFound
+
+type ProjectService struct {
+	projects repositories.ProjectRepository
+}
+
+func NewProjectService(projects repositories.ProjectRepository) *ProjectService {
+	return &ProjectService{projects: projects}
+}
+
+func (s *ProjectService) CreateProject(name string, description string) (*models.Project, error) {
+	project := &models.Project{Name: strings.TrimSpace(name), Description: description}
+	if err := validateProject(project); err != nil {
+		return nil, err
+	}
+
+	if err := s.projects.Create(project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (s *ProjectService) ListProjects() ([]models.Project, error) {
+	return s.projects.List(repositories.ProjectQuery{})
+}
+
+func (s *ProjectService) FindProject(projectID string) (*models.Project, error) {
+	id, err := parseID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("There is no project with id %s", projectID)
+	}
+
+	project, err := s.projects.FindByID(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("There is no project with id %s", projectID)
+	}
+
+	return project, err
+}
+
+// UpdateProject overwrites the name and description of project. project is
+// left untouched if validation fails.
+func (s *ProjectService) UpdateProject(project *models.Project, name string, description string) error {
+	updated := *project
+	updated.Name = strings.TrimSpace(name)
+	updated.Description = description
+	if err := validateProject(&updated); err != nil {
+		return err
+	}
+
+	if err := s.projects.Update(&updated); err != nil {
+		return err
+	}
+
+	*project = updated
+
+	return nil
+}
+
+// DeleteProject deletes project. Its owner's todos move to the trash if
+// trash is set and to the inbox otherwise.
+func (s *ProjectService) DeleteProject(project *models.Project, trash bool) error {
+	return s.projects.Delete(project.ID, trash)
+}
+
+func validateProject(project *models.Project) error {
+	if n := utf8.RuneCountInString(project.Name); n == 0 || n > 100 {
+		return &ValidationError{Field: "name", Message: "Project name have to be 1 to 100 letters"}
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/recurrence"
+	"example/Studying/repositories"
+	"fmt"
+)
+
+// EditScope says which occurrences of a recurring todo an edit applies to.
+type EditScope string
+
+const (
+	// EditThis changes only the occurrence being edited.
+	EditThis EditScope = "this"
+	// EditFuture also changes the series, and so every occurrence still to
+	// be created. It is the only scope that can change or end the rule.
+	EditFuture EditScope = "future"
+)
+
+var (
+	ErrInvalidScope = errors.New("Scope have to be this or future")
+	// ErrNoSeries means recurring todos were used on a TodoService without
+	// WithSeries.
+	ErrNoSeries = errors.New("Recurring todos are not available")
+)
+
+// ParseEditScope reads a scope name; an empty name means EditThis.
+func ParseEditScope(name string) (EditScope, error) {
+	switch scope := EditScope(name); scope {
+	case "":
+		return EditThis, nil
+	case EditThis, EditFuture:
+		return scope, nil
+	}
+
+	return "", ErrInvalidScope
+}
+
+// FindSeries returns the series recurring todos point to with SeriesID. A
+// user sees the series they own and the ones with an occurrence they can
+// see, shared or in a project of theirs.
+func (s *TodoService) FindSeries(seriesID string) (*models.Series, error) {
+	id, err := parseID(seriesID)
+	if err != nil || s.series == nil {
+		return nil, fmt.Errorf("There is no series with id %s", seriesID)
+	}
+
+	series, err := s.series.FindByID(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("There is no series with id %s", seriesID)
+	}
+	if err != nil || s.owner == nil || series.OwnerID == *s.owner {
+		return series, err
+	}
+
+	visible, err := s.repo.Count(repositories.TodoQuery{SeriesID: &series.ID})
+	if err != nil {
+		return nil, err
+	}
+	if visible == 0 {
+		return nil, fmt.Errorf("There is no series with id %s", seriesID)
+	}
+
+	return series, nil
+}
+
+// createOccurrence creates todo as the first occurrence of a new series.
+func (s *TodoService) createOccurrence(todo *models.ToDo) error {
+	if s.series == nil {
+		return ErrNoSeries
+	}
+
+	series := seriesOf(todo)
+	if err := s.series.Create(series); err != nil {
+		return err
+	}
+
+	todo.SeriesID = &series.ID
+	if err := s.repo.Create(todo); err != nil {
+		return err
+	}
+
+	series.LatestID = todo.ID
+	return s.series.Update(series)
+}
+
+// reschedule works out how replacing old with todo changes recurrence. A
+// todo that starts to recur begins a new series right away; with EditFuture
+// the changed series is returned for the caller to save along with todo.
+func (s *TodoService) reschedule(old *models.ToDo, todo *models.ToDo, scope EditScope) (*models.Series, error) {
+	if old.RRule == "" {
+		if todo.RRule == "" {
+			return nil, nil
+		}
+		if s.series == nil {
+			return nil, ErrNoSeries
+		}
+
+		series := seriesOf(todo)
+		series.LatestID = todo.ID
+		if err := s.series.Create(series); err != nil {
+			return nil, err
+		}
+		todo.SeriesID = &series.ID
+		return nil, nil
+	}
+
+	if scope != EditFuture {
+		if todo.RRule != old.RRule {
+			return nil, &ValidationError{Field: "rrule", Message: "Recurrence can only be changed for all future occurrences, use scope=future"}
+		}
+		return nil, nil
+	}
+	if s.series == nil {
+		return nil, ErrNoSeries
+	}
+
+	series, err := s.series.FindByID(*todo.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := series.Start
+	if todo.RRule != "" && (todo.RRule != old.RRule || !todo.DueAt.Equal(*old.DueAt)) {
+		// The rule starts over from this occurrence. An unchanged rule keeps
+		// the number of occurrences it had left.
+		if todo.RRule == old.RRule {
+			rule, err := recurrence.Parse(todo.RRule)
+			if err != nil {
+				return nil, err
+			}
+			if rule.Count > 0 {
+				rule.Count = max(rule.Count-rule.Before(series.Start, *old.DueAt), 1)
+				todo.RRule = rule.String()
+			}
+		}
+		start = *todo.DueAt
+	}
+
+	updated := seriesOf(todo)
+	updated.ID, updated.LatestID, updated.CreatedAt = series.ID, series.LatestID, series.CreatedAt
+	updated.Start = start
+
+	return updated, nil
+}
+
+// nextOccurrence creates the occurrence after todo, which was just
+// completed, if todo is the latest occurrence of a series that goes on.
+func (s *TodoService) nextOccurrence(todo *models.ToDo) (*models.ToDo, error) {
+	if todo.SeriesID == nil || todo.RRule == "" || todo.DueAt == nil || s.series == nil {
+		return nil, nil
+	}
+
+	series, err := s.series.FindByID(*todo.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	if series.RRule == "" || series.LatestID != todo.ID {
+		return nil, nil
+	}
+
+	rule, err := recurrence.Parse(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+	due, ok := rule.Next(series.Start, *todo.DueAt)
+	if !ok {
+		return nil, nil
+	}
+
+	next := &models.ToDo{
+		OwnerID:   series.OwnerID,
+		Title:     series.Title,
+		Body:      series.Body,
+		Priority:  series.Priority,
+		State:     s.workflow.Initial,
+		DueAt:     &due,
+		ProjectID: series.ProjectID,
+		RRule:     series.RRule,
+		SeriesID:  &series.ID,
+		Tags:      []models.Tag{},
+	}
+	if series.StartBefore != nil {
+		start := due.Add(-*series.StartBefore)
+		next.StartAt = &start
+	}
+
+	if err := s.series.Advance(series, next); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			// Somebody else completed it first and created the next one.
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := s.copyShares(todo, next); err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// copyShares shares next with everybody todo was shared with directly, so
+// the series stays shared from one occurrence to the next. Shares of the
+// project come along with ProjectID.
+func (s *TodoService) copyShares(todo *models.ToDo, next *models.ToDo) error {
+	if s.shares == nil {
+		return nil
+	}
+
+	shares, err := s.shares.ListForTodo(todo.ID)
+	if err != nil {
+		return err
+	}
+	for _, share := range shares {
+		copied := &models.Share{OwnerID: share.OwnerID, TodoID: &next.ID, UserID: share.UserID, Role: share.Role}
+		if err := s.shares.Create(copied); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seriesOf makes a series template out of an occurrence.
+func seriesOf(todo *models.ToDo) *models.Series {
+	series := &models.Series{
+		OwnerID:   todo.OwnerID,
+		RRule:     todo.RRule,
+		Title:     todo.Title,
+		Body:      todo.Body,
+		Priority:  todo.Priority,
+		ProjectID: todo.ProjectID,
+	}
+	if todo.DueAt != nil {
+		series.Start = *todo.DueAt
+		if todo.StartAt != nil {
+			lead := todo.DueAt.Sub(*todo.StartAt)
+			series.StartBefore = &lead
+		}
+	}
+
+	return series
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"strings"
+)
+
+var (
+	ErrInvalidRole = errors.New("Role have to be one of " + strings.Join(models.RoleNames(), ", "))
+	// ErrLastAdmin means the change would leave nobody able to assign roles.
+	ErrLastAdmin = repositories.ErrLastAdmin
+)
+
+// ListUsers returns every user in the order they registered.
+func (s *AuthService) ListUsers() ([]models.User, error) {
+	return s.users.List()
+}
+
+// SetRole gives the user with userID the role called role. Users keep their
+// old role in access tokens they already have, until those are refreshed.
+func (s *AuthService) SetRole(userID string, role string) (*models.User, error) {
+	newRole, ok := models.ParseRole(role)
+	if !ok {
+		return nil, ErrInvalidRole
+	}
+
+	id, err := parseID(userID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	user, err := s.users.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == newRole {
+		return user, nil
+	}
+
+	if err := s.users.SetRole(user.ID, newRole); err != nil {
+		return nil, err
+	}
+	user.Role = newRole
+
+	return user, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/repositories"
+	"strings"
+	"unicode/utf8"
+)
+
+const MaxSearchLength = 256
+
+var ErrInvalidSearch = errors.New("Search query must be between 1 and 256 characters")
+
+type SearchPage struct {
+	Hits   []repositories.SearchHit
+	Limit  int
+	Offset int
+}
+
+// SearchTodos ranks todos whose title or body match text. Search results are
+// ordered by relevance, so only offset paging applies.
+func (s *TodoService) SearchTodos(text string, page PageRequest) (*SearchPage, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > MaxSearchLength {
+		return nil, ErrInvalidSearch
+	}
+	if page.Limit < 0 || page.Offset < 0 || page.Cursor != "" || page.Sort != "" {
+		return nil, ErrInvalidPage
+	}
+
+	limit := page.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	query := repositories.TodoQuery{Page: repositories.Page{Limit: limit, Offset: page.Offset}}
+	hits, err := s.repo.Search(text, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchPage{Hits: hits, Limit: limit, Offset: page.Offset}, nil
+}
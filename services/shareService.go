@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"strings"
+)
+
+var (
+	ErrDuplicateShare = repositories.ErrDuplicateShare
+	// ErrForbidden means a todo or project is shared with the caller, but
+	// not in a way that allows what they tried.
+	ErrForbidden = repositories.ErrForbidden
+)
+
+// roleOf tells what the caller of repo may do with todo. Repositories that
+// aren't limited to a user allow everything.
+func roleOf(repo repositories.TodoRepository, todo *models.ToDo) (models.ShareRole, error) {
+	if owned, ok := repo.(*repositories.OwnedTodoRepository); ok {
+		return owned.Role(todo)
+	}
+
+	return models.ShareOwner, nil
+}
+
+// requireRole fails with ErrForbidden unless the caller of repo has role
+// for todo.
+func requireRole(repo repositories.TodoRepository, todo *models.ToDo, role models.ShareRole) error {
+	granted, err := roleOf(repo, todo)
+	if err != nil {
+		return err
+	}
+	if !granted.Includes(role) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// projectRoleOf tells what the caller of repo may do with project.
+// Repositories that aren't limited to a user allow everything.
+func projectRoleOf(repo repositories.ProjectRepository, project *models.Project) (models.ShareRole, error) {
+	if owned, ok := repo.(*repositories.OwnedProjectRepository); ok {
+		return owned.Role(project)
+	}
+
+	return models.ShareOwner, nil
+}
+
+// RequireRole fails with ErrForbidden unless the user the service acts for
+// has role for todo: they own it or it is shared with them that way.
+func (s *TodoService) RequireRole(todo *models.ToDo, role models.ShareRole) error {
+	return requireRole(s.repo, todo, role)
+}
+
+// ShareService shares the todos a user can see with other users, one by one
+// or all of the owner's todos in a project at once.
+type ShareService struct {
+	shares   repositories.ShareRepository
+	users    repositories.UserRepository
+	projects repositories.ProjectRepository
+	todos    repositories.TodoRepository
+	userID   uint
+}
+
+// NewShareService creates a service acting for the user with userID, whose
+// view of the projects and todos is projects and todos.
+func NewShareService(shares repositories.ShareRepository, users repositories.UserRepository, projects repositories.ProjectRepository, todos repositories.TodoRepository, userID uint) *ShareService {
+	return &ShareService{shares: shares, users: users, projects: projects, todos: todos, userID: userID}
+}
+
+// ListTodoShares returns who todo is shared with on its own, oldest first.
+func (s *ShareService) ListTodoShares(todo *models.ToDo) ([]models.Share, error) {
+	shares, err := s.shares.ListForTodo(todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withUsers(shares)
+}
+
+// ShareTodo shares todo with the user whose username or email is login.
+// Only owners of the todo may share it.
+func (s *ShareService) ShareTodo(todo *models.ToDo, login string, role string) (*models.Share, error) {
+	if err := requireRole(s.todos, todo, models.ShareOwner); err != nil {
+		return nil, err
+	}
+
+	share := &models.Share{OwnerID: todo.OwnerID, TodoID: &todo.ID}
+	if err := s.grant(share, login, role); err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// RevokeTodoShare deletes a share of todo. Owners of the todo may revoke
+// any of its shares, other users only their own.
+func (s *ShareService) RevokeTodoShare(todo *models.ToDo, shareID string) error {
+	share, err := s.findShare(shareID)
+	if err != nil {
+		return err
+	}
+	if share.TodoID == nil || *share.TodoID != todo.ID {
+		return ErrNotFound
+	}
+	if share.UserID != s.userID {
+		if err := requireRole(s.todos, todo, models.ShareOwner); err != nil {
+			return err
+		}
+	}
+
+	return s.shares.Delete(share.ID)
+}
+
+// ListProjectShares returns who the project with projectID is shared with,
+// oldest first.
+func (s *ShareService) ListProjectShares(projectID string) ([]models.Share, error) {
+	project, err := s.findProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.shares.ListForProject(project.OwnerID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withUsers(shares)
+}
+
+// ShareProject shares the project with projectID and its owner's todos in
+// it, now and later ones, with the user whose username or email is login.
+// Only owners of the project may share it.
+func (s *ShareService) ShareProject(projectID string, login string, role string) (*models.Share, error) {
+	project, err := s.findProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireProjectRole(project, models.ShareOwner); err != nil {
+		return nil, err
+	}
+
+	share := &models.Share{OwnerID: project.OwnerID, ProjectID: &project.ID}
+	if err := s.grant(share, login, role); err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// RevokeProjectShare deletes a share of the project with projectID. Owners
+// of the project may revoke any of its shares, other users only their own.
+func (s *ShareService) RevokeProjectShare(projectID string, shareID string) error {
+	project, err := s.findProject(projectID)
+	if err != nil {
+		return err
+	}
+
+	share, err := s.findShare(shareID)
+	if err != nil {
+		return err
+	}
+	if share.ProjectID == nil || *share.ProjectID != project.ID || share.OwnerID != project.OwnerID {
+		return ErrNotFound
+	}
+	if share.UserID != s.userID {
+		if err := s.requireProjectRole(project, models.ShareOwner); err != nil {
+			return err
+		}
+	}
+
+	return s.shares.Delete(share.ID)
+}
+
+// requireProjectRole fails with ErrForbidden unless the user has role for
+// project.
+func (s *ShareService) requireProjectRole(project *models.Project, role models.ShareRole) error {
+	granted, err := projectRoleOf(s.projects, project)
+	if err != nil {
+		return err
+	}
+	if !granted.Includes(role) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// grant completes share with the user whose username or email is login and
+// role, and stores it.
+func (s *ShareService) grant(share *models.Share, login string, role string) error {
+	shareRole, ok := models.ParseShareRole(role)
+	if !ok {
+		return &ValidationError{Field: "role", Message: "Role have to be one of viewer, editor, owner"}
+	}
+
+	user, err := s.users.FindByLogin(strings.ToLower(strings.TrimSpace(login)))
+	if errors.Is(err, ErrNotFound) {
+		return &ValidationError{Field: "user", Message: "There is no user with this username or email"}
+	}
+	if err != nil {
+		return err
+	}
+	if user.ID == share.OwnerID || user.ID == s.userID {
+		return &ValidationError{Field: "user", Message: "Can't share with the owner or yourself"}
+	}
+
+	share.UserID, share.Role = user.ID, shareRole
+	if err := s.shares.Create(share); err != nil {
+		return err
+	}
+	share.User = user
+
+	return nil
+}
+
+// withUsers fills in who each share is with.
+func (s *ShareService) withUsers(shares []models.Share) ([]models.Share, error) {
+	for i := range shares {
+		user, err := s.users.FindByID(shares[i].UserID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		shares[i].User = user
+	}
+
+	return shares, nil
+}
+
+func (s *ShareService) findShare(shareID string) (*models.Share, error) {
+	id, err := parseID(shareID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return s.shares.FindByID(id)
+}
+
+func (s *ShareService) findProject(projectID string) (*models.Project, error) {
+	id, err := parseID(projectID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return s.projects.FindByID(id)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"example/Studying/repositories"
+	"fmt"
+	"strings"
+)
+
+// ParseSort reads a comma separated sort spec such as "-created_at,title" or
+// "status:asc,updated_at:desc". A leading "-" or a ":desc" suffix sorts the
+// field descending. Only whitelisted fields are accepted. An empty spec or
+// "smart" selects the default ordering by priority and due date.
+func ParseSort(spec string) ([]repositories.SortField, error) {
+	if spec == "" || spec == "smart" {
+		return nil, nil
+	}
+
+	var fields []repositories.SortField
+	seen := map[string]bool{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+
+		var field repositories.SortField
+		name, direction, hasDirection := strings.Cut(item, ":")
+		switch {
+		case hasDirection && direction == "asc":
+		case hasDirection && direction == "desc":
+			field.Desc = true
+		case hasDirection:
+			return nil, fmt.Errorf("%w %q", ErrInvalidSort, item)
+		case strings.HasPrefix(name, "-"):
+			name, field.Desc = name[1:], true
+		case strings.HasPrefix(name, "+"):
+			name = name[1:]
+		}
+
+		if !repositories.IsSortable(name) || seen[name] {
+			return nil, fmt.Errorf("%w %q", ErrInvalidSort, item)
+		}
+		seen[name] = true
+
+		field.Field = name
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func formatSort(fields []repositories.SortField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Field
+		if field.Desc {
+			parts[i] = "-" + field.Field
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// SubtaskService manages the checklist of a todo. A todo with subtasks is
+// kept done exactly when all of them are: completing the last open subtask
+// completes the todo, and reopening or adding one reopens it, as far as the
+// workflow of todos allows.
+type SubtaskService struct {
+	subtasks repositories.SubtaskRepository
+	todos    *TodoService
+}
+
+func NewSubtaskService(subtasks repositories.SubtaskRepository, todos *TodoService) *SubtaskService {
+	return &SubtaskService{subtasks: subtasks, todos: todos}
+}
+
+// SubtaskFields are the client-editable fields of a subtask. Nil fields are
+// left as they are, and a nil Position appends a new subtask.
+type SubtaskFields struct {
+	Title    *string
+	Done     *bool
+	Position *int
+}
+
+func (s *SubtaskService) ListSubtasks(todo *models.ToDo) ([]models.Subtask, error) {
+	return s.subtasks.List(todo.ID)
+}
+
+func (s *SubtaskService) FindSubtask(todo *models.ToDo, subtaskID string) (*models.Subtask, error) {
+	id, err := parseID(subtaskID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return s.subtasks.FindByID(todo.ID, id)
+}
+
+func (s *SubtaskService) CreateSubtask(todo *models.ToDo, fields SubtaskFields) (*models.Subtask, error) {
+	if err := fields.validate(); err != nil {
+		return nil, err
+	}
+
+	subtask := &models.Subtask{TodoID: todo.ID}
+	fields.applyTo(subtask)
+
+	if err := validateSubtask(subtask); err != nil {
+		return nil, err
+	}
+
+	if err := s.subtasks.Create(subtask); err != nil {
+		return nil, err
+	}
+
+	return subtask, s.syncStatus(todo.ID)
+}
+
+// UpdateSubtask changes the given fields of subtask. subtask is left
+// untouched if validation fails.
+func (s *SubtaskService) UpdateSubtask(subtask *models.Subtask, fields SubtaskFields) error {
+	if err := fields.validate(); err != nil {
+		return err
+	}
+
+	updated := *subtask
+	fields.applyTo(&updated)
+
+	if err := validateSubtask(&updated); err != nil {
+		return err
+	}
+
+	if err := s.subtasks.Update(&updated); err != nil {
+		return err
+	}
+
+	*subtask = updated
+
+	return s.syncStatus(subtask.TodoID)
+}
+
+func (s *SubtaskService) DeleteSubtask(subtask *models.Subtask) error {
+	if err := s.subtasks.Delete(subtask.TodoID, subtask.ID); err != nil {
+		return err
+	}
+
+	return s.syncStatus(subtask.TodoID)
+}
+
+// Completion is the percentage of done subtasks of a todo, or nil if it has
+// none.
+func (s *SubtaskService) Completion(todo *models.ToDo) (*int, error) {
+	subtasks, err := s.subtasks.List(todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Completion(subtasks), nil
+}
+
+// Completion is the percentage of done subtasks, rounded down, or nil if
+// there are none.
+func Completion(subtasks []models.Subtask) *int {
+	if len(subtasks) == 0 {
+		return nil
+	}
+
+	done := 0
+	for _, subtask := range subtasks {
+		if subtask.Done {
+			done++
+		}
+	}
+
+	percent := int(math.Floor(float64(done) * 100 / float64(len(subtasks))))
+	return &percent
+}
+
+// syncStatus marks a todo with subtasks done exactly when all of them are,
+// reopening it as in progress otherwise. Cancelled todos, and todos the
+// workflow doesn't let move, are left alone.
+func (s *SubtaskService) syncStatus(todoID uint) error {
+	subtasks, err := s.subtasks.List(todoID)
+	if err != nil || len(subtasks) == 0 {
+		return err
+	}
+
+	todo, err := s.todos.repo.FindByID(todoID)
+	if err != nil {
+		return err
+	}
+
+	done := *Completion(subtasks) == 100
+	if todo.Status == done || todo.State == models.StateCancelled {
+		return nil
+	}
+
+	fields := fieldsOf(todo)
+	fields.State, fields.Status = string(models.StateInProgress), done
+	if done {
+		fields.State = string(models.StateDone)
+	}
+
+	// Completing a recurring todo this way creates its next occurrence too.
+	var transitionErr *TransitionError
+	if err := s.todos.ReplaceTodo(todo, fields); err != nil && !errors.As(err, &transitionErr) {
+		return err
+	}
+
+	return nil
+}
+
+func (f SubtaskFields) validate() error {
+	if f.Position != nil && *f.Position < 1 {
+		return &ValidationError{Field: "position", Message: "Position have to be positive"}
+	}
+
+	return nil
+}
+
+func (f SubtaskFields) applyTo(subtask *models.Subtask) {
+	if f.Title != nil {
+		subtask.Title = strings.TrimSpace(*f.Title)
+	}
+	if f.Done != nil {
+		subtask.Done = *f.Done
+	}
+	if f.Position != nil {
+		subtask.Position = *f.Position
+	}
+}
+
+func validateSubtask(subtask *models.Subtask) error {
+	if n := utf8.RuneCountInString(subtask.Title); n == 0 || n > 200 {
+		return &ValidationError{Field: "title", Message: "Subtask title have to be 1 to 200 letters"}
+	}
+
+	return nil
+}
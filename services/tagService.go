@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrDuplicateTag means another tag of the user already has the name.
+	ErrDuplicateTag = repositories.ErrDuplicateTag
+	ErrInvalidTags  = errors.New("Wrong tags format, use comma separated tag names")
+)
+
+// TagService manages the tags of one user and labels the todos they can
+// change with them.
+type TagService struct {
+	tags   repositories.TagRepository
+	todos  repositories.TodoRepository
+	userID uint
+}
+
+// NewTagService creates a service acting for the user with userID, whose
+// view of the todos is todos.
+func NewTagService(tags repositories.TagRepository, todos repositories.TodoRepository, userID uint) *TagService {
+	return &TagService{tags: tags, todos: todos, userID: userID}
+}
+
+func (s *TagService) CreateTag(name string) (*models.Tag, error) {
+	tag := &models.Tag{OwnerID: s.userID, Name: strings.TrimSpace(name)}
+	if err := validateTag(tag); err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.Create(tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) ListTags() ([]models.Tag, error) {
+	return s.tags.List(s.userID)
+}
+
+// FindTag returns a tag of the user; tags of others look like they do not
+// exist.
+func (s *TagService) FindTag(tagID string) (*models.Tag, error) {
+	id, err := parseID(tagID)
+	if err != nil {
+		return nil, fmt.Errorf("There is no tag with id %s", tagID)
+	}
+
+	tag, err := s.ownTag(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("There is no tag with id %s", tagID)
+	}
+
+	return tag, err
+}
+
+// RenameTag changes the name of tag, which every todo labelled with it sees.
+func (s *TagService) RenameTag(tag *models.Tag, name string) error {
+	renamed := *tag
+	renamed.Name = strings.TrimSpace(name)
+	if err := validateTag(&renamed); err != nil {
+		return err
+	}
+
+	if err := s.tags.Update(&renamed); err != nil {
+		return err
+	}
+
+	*tag = renamed
+
+	return nil
+}
+
+// DeleteTag deletes tag and detaches it from every todo.
+func (s *TagService) DeleteTag(tag *models.Tag) error {
+	return s.tags.Delete(tag.ID)
+}
+
+// AttachTag labels a todo with a tag of the user and returns the updated
+// todo.
+func (s *TagService) AttachTag(todoID string, tagID string) (*models.ToDo, error) {
+	return s.relabel(todoID, tagID, func(todo, tag uint) error {
+		if _, err := s.ownTag(tag); err != nil {
+			return err
+		}
+
+		return s.tags.Attach(todo, tag)
+	})
+}
+
+// DetachTag removes a tag from a todo and returns the updated todo. Any tag
+// can be removed, so that tags others put on a todo don't stick to it.
+func (s *TagService) DetachTag(todoID string, tagID string) (*models.ToDo, error) {
+	return s.relabel(todoID, tagID, s.tags.Detach)
+}
+
+func (s *TagService) relabel(todoID string, tagID string, change func(todoID, tagID uint) error) (*models.ToDo, error) {
+	todo, errTodo := parseID(todoID)
+	tag, errTag := parseID(tagID)
+	if errTodo != nil || errTag != nil {
+		return nil, ErrNotFound
+	}
+
+	// Make sure the todo is one the caller may change before labelling it.
+	found, err := s.todos.FindByID(todo)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireRole(s.todos, found, models.ShareEditor); err != nil {
+		return nil, err
+	}
+	if err := change(todo, tag); err != nil {
+		return nil, err
+	}
+
+	return s.todos.FindByID(todo)
+}
+
+func (s *TagService) ownTag(id uint) (*models.Tag, error) {
+	tag, err := s.tags.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.OwnerID != s.userID {
+		return nil, ErrNotFound
+	}
+
+	return tag, nil
+}
+
+// parseTagNames splits a comma separated list of tag names.
+func parseTagNames(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, ErrInvalidTags
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+func validateTag(tag *models.Tag) error {
+	if n := utf8.RuneCountInString(tag.Name); n == 0 || n > 64 || strings.Contains(tag.Name, ",") {
+		return &ValidationError{Field: "name", Message: "Tag name have to be 1 to 64 letters without commas"}
+	}
+
+	return nil
+}
@@ -1,85 +1,283 @@
 package services
 
 import (
-	"example/Studying/initializers"
+	"errors"
+	"example/Studying/filters"
 	"example/Studying/models"
+	"example/Studying/repositories"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrVersionConflict means the todo changed between reading and writing it.
+	ErrVersionConflict = repositories.ErrVersionConflict
+	// ErrNotFound means a todo, or something it refers to, does not exist.
+	ErrNotFound = repositories.ErrNotFound
 )
 
 type TodoService struct {
-	Title  string `json:"title"`
-	Body   string `json:"body"`
-	Status bool   `json:"status"`
+	repo        repositories.TodoRepository
+	series      repositories.SeriesRepository
+	shares      repositories.ShareRepository
+	workflow    *Workflow
+	attachments *AttachmentService
+	// owner is the user the service acts for, nil for one that sees every
+	// todo, such as the one the trash purge job uses.
+	owner *uint
 }
 
-func NewTodoService() *TodoService {
-	return &TodoService{}
+// NewTodoService creates a service for the todos in repo, following
+// DefaultWorkflow. Todos can only recur once WithSeries has been called.
+func NewTodoService(repo repositories.TodoRepository) *TodoService {
+	return &TodoService{repo: repo, workflow: DefaultWorkflow()}
+}
+
+// WithAttachments makes purging todos also remove their attachments.
+func (s *TodoService) WithAttachments(attachments *AttachmentService) *TodoService {
+	s.attachments = attachments
+	return s
+}
+
+// WithWorkflow makes the service enforce workflow instead of the default.
+func (s *TodoService) WithWorkflow(workflow *Workflow) *TodoService {
+	s.workflow = workflow
+	return s
+}
+
+// ForUser limits the service to the todos of the user with userID and, if
+// shares isn't nil, the todos shared with them; next occurrences of shared
+// recurring todos are then shared the same way. Other todos look like they
+// do not exist, and new todos belong to the user. If projects isn't nil,
+// todos only go into projects the user may edit.
+func (s *TodoService) ForUser(userID uint, shares repositories.ShareRepository, projects repositories.ProjectRepository) *TodoService {
+	owned := repositories.NewOwnedTodoRepository(s.repo, userID)
+	if shares != nil {
+		owned.WithShares(shares)
+		s.shares = shares
+	}
+	if projects != nil {
+		owned.WithProjects(projects)
+	}
+	s.repo = owned
+	s.owner = &userID
+	return s
+}
+
+// WithSeries enables recurring todos, whose series are kept in series.
+func (s *TodoService) WithSeries(series repositories.SeriesRepository) *TodoService {
+	s.series = series
+	return s
 }
 
 func (s *TodoService) CreateTodo(title string, body string, status bool) (*models.ToDo, error) {
-	todo := &models.ToDo{
-		Title:  title,
-		Body:   body,
-		Status: status,
+	return s.CreateTodoFields(TodoFields{Title: title, Body: body, Status: status})
+}
+
+// CreateTodoFields creates a todo from the full set of editable fields.
+func (s *TodoService) CreateTodoFields(fields TodoFields) (*models.ToDo, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	todo := &models.ToDo{Tags: []models.Tag{}}
+	if s.owner != nil {
+		todo.OwnerID = *s.owner
 	}
+	fields.applyTo(todo)
 
-	if err := initializers.DB.Create(todo).Error; err != nil {
+	if err := validateTodo(todo); err != nil {
+		return nil, err
+	}
+	if err := s.workflow.transition(nil, todo, fields); err != nil {
+		return nil, err
+	}
+
+	if todo.RRule != "" {
+		return todo, s.createOccurrence(todo)
+	}
+
+	if err := s.repo.Create(todo); err != nil {
 		return nil, err
 	}
 
 	return todo, nil
 }
 
-func (s *TodoService) GetAllTodos() ([]models.ToDo, error) {
-	var todos []models.ToDo
+// TodoFilter narrows a listing. Expr is a filter expression in the language
+// of package filters; Status keeps the older ?status= filter working.
+// States and Priorities are comma separated lists of names, any of which
+// matches; AnyTags and AllTags are comma separated tag names. ProjectID
+// limits the listing to one project.
+type TodoFilter struct {
+	Status     *bool
+	ProjectID  *uint
+	States     string
+	Priorities string
+	AnyTags    string
+	AllTags    string
+	Expr       string
+	Due        DueFilter
+}
+
+func (s *TodoService) ListTodos(filter TodoFilter, page PageRequest) (*TodoPage, error) {
+	query := repositories.TodoQuery{Status: filter.Status, ProjectID: filter.ProjectID}
+
+	if filter.States != "" {
+		for _, name := range strings.Split(filter.States, ",") {
+			state, ok := models.ParseState(strings.TrimSpace(name))
+			if !ok {
+				return nil, ErrInvalidState
+			}
+			query.States = append(query.States, state)
+		}
+	}
+
+	if filter.Priorities != "" {
+		for _, name := range strings.Split(filter.Priorities, ",") {
+			name = strings.TrimSpace(name)
+			priority, ok := models.ParsePriority(name)
+			if !ok || name == "" {
+				return nil, ErrInvalidPriority
+			}
+			query.Priorities = append(query.Priorities, priority)
+		}
+	}
+
+	var err error
+	if filter.AnyTags != "" {
+		if query.AnyTags, err = parseTagNames(filter.AnyTags); err != nil {
+			return nil, err
+		}
+	}
+	if filter.AllTags != "" {
+		if query.AllTags, err = parseTagNames(filter.AllTags); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Expr != "" {
+		node, err := filters.Parse(filter.Expr, repositories.TodoFilterSchema())
+		if err != nil {
+			return nil, err
+		}
+		query.Filter = node
+	}
 
-	if err := initializers.DB.Find(&todos).Error; err != nil {
+	due, err := filter.Due.condition(time.Now())
+	if err != nil {
 		return nil, err
 	}
+	query.Filter = and(query.Filter, due)
 
-	return todos, nil
+	return s.listPage(query, page)
 }
 
-func (s *TodoService) FindTodo(todoID string) (*models.ToDo, error) {
-	var todo models.ToDo
+func (s *TodoService) GetAllTodos(page PageRequest) (*TodoPage, error) {
+	return s.ListTodos(TodoFilter{}, page)
+}
 
-	if err := initializers.DB.First(&todo, todoID).Error; err != nil {
+func (s *TodoService) FindTodo(todoID string) (*models.ToDo, error) {
+	id, err := parseID(todoID)
+	if err != nil {
 		return nil, err
 	}
 
-	return &todo, nil
+	return s.repo.FindByID(id)
 }
 
-func (s *TodoService) FindByStatus(status bool) ([]models.ToDo, error) {
-	var todos []models.ToDo
+func (s *TodoService) FindByStatus(status bool, page PageRequest) (*TodoPage, error) {
+	return s.ListTodos(TodoFilter{Status: &status}, page)
+}
+
+// UpdateTodo changes the title, body and status of todo, keeping its other
+// fields. Completing a recurring todo creates its next occurrence.
+func (s *TodoService) UpdateTodo(todo *models.ToDo, title string, body string, status bool) error {
+	fields := fieldsOf(todo)
+	fields.Title, fields.Body, fields.Status = title, body, status
+
+	return s.ReplaceTodo(todo, fields)
+}
+
+// ReplaceTodo overwrites every editable field of todo with fields. todo is
+// left untouched if validation fails. A recurring todo is edited alone, as
+// with EditThis.
+func (s *TodoService) ReplaceTodo(todo *models.ToDo, fields TodoFields) error {
+	_, err := s.ReplaceTodoScoped(todo, fields, EditThis)
+	return err
+}
+
+// ReplaceTodoScoped is ReplaceTodo for recurring todos: with EditFuture the
+// changes also apply to the occurrences still to come. If the change
+// completes the latest occurrence of a series, the next one is created and
+// returned.
+func (s *TodoService) ReplaceTodoScoped(todo *models.ToDo, fields TodoFields, scope EditScope) (*models.ToDo, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	updated := *todo
+	fields.applyTo(&updated)
+
+	if err := validateTodo(&updated); err != nil {
+		return nil, err
+	}
+	if err := s.workflow.transition(todo, &updated, fields); err != nil {
+		return nil, err
+	}
+
+	series, err := s.reschedule(todo, &updated, scope)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := initializers.DB.Where("status = ?", status).Find(&todos).Error; err != nil {
+	if err := s.repo.Update(&updated); err != nil {
 		return nil, err
 	}
+	if series != nil {
+		if err := s.series.Update(series); err != nil {
+			return nil, err
+		}
+	}
 
-	return todos, nil
+	completed := updated.Status && !todo.Status
+	*todo = updated
+
+	if !completed {
+		return nil, nil
+	}
+
+	return s.nextOccurrence(todo)
 }
 
-func (s *TodoService) UpdateTodo(todo *models.ToDo, title string, body string, status bool) error {
-	updateFields := map[string]interface{}{
-		"Title":  title,
-		"Body":   body,
-		"Status": status,
+func (s *TodoService) DeleteTodo(todoID string) error {
+	id, err := parseID(todoID)
+	if err != nil {
+		return fmt.Errorf("There is no todo with id %s", todoID)
 	}
 
-	if err := initializers.DB.Model(todo).Updates(updateFields).Error; err != nil {
+	if err := s.repo.Delete(id, 0); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return fmt.Errorf("There is no todo with id %s", todoID)
+		}
 		return err
 	}
 
 	return nil
 }
 
-func (s *TodoService) DeleteTodo(todoID string) error {
-	result := initializers.DB.Delete(&models.ToDo{}, todoID)
+// DeleteTodoVersion deletes todo only if nobody changed it since it was read.
+func (s *TodoService) DeleteTodoVersion(todo *models.ToDo) error {
+	return s.repo.Delete(todo.ID, todo.Version)
+}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("There is no todo with id %s", todoID)
+func parseID(todoID string) (uint, error) {
+	id, err := strconv.ParseUint(todoID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid todo id %s", todoID)
 	}
 
-	return nil
+	return uint(id), nil
 }
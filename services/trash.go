@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"fmt"
+	"time"
+)
+
+// ListTrash pages through soft-deleted todos.
+func (s *TodoService) ListTrash(page PageRequest) (*TodoPage, error) {
+	return s.listPage(repositories.TodoQuery{Trashed: true}, page)
+}
+
+// FindTodoUnscoped finds a todo whether or not it is in the trash.
+func (s *TodoService) FindTodoUnscoped(todoID string) (*models.ToDo, error) {
+	id, err := parseID(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByIDUnscoped(id)
+}
+
+// RestoreTodo takes a todo out of the trash and returns it.
+func (s *TodoService) RestoreTodo(todoID string) (*models.ToDo, error) {
+	id, err := parseID(todoID)
+	if err != nil {
+		return nil, fmt.Errorf("There is no deleted todo with id %s", todoID)
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("There is no deleted todo with id %s", todoID)
+		}
+		return nil, err
+	}
+
+	return s.repo.FindByID(id)
+}
+
+// PurgeTodo permanently deletes a live or trashed todo.
+func (s *TodoService) PurgeTodo(todoID string) error {
+	id, err := parseID(todoID)
+	if err != nil {
+		return fmt.Errorf("There is no todo with id %s", todoID)
+	}
+
+	if err := s.repo.Purge(id, 0); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return fmt.Errorf("There is no todo with id %s", todoID)
+		}
+		return err
+	}
+
+	s.removeOrphans()
+	return nil
+}
+
+// PurgeTodoVersion permanently deletes todo only if nobody changed it since
+// it was read.
+func (s *TodoService) PurgeTodoVersion(todo *models.ToDo) error {
+	if err := s.repo.Purge(todo.ID, todo.Version); err != nil {
+		return err
+	}
+
+	s.removeOrphans()
+	return nil
+}
+
+// PurgeTrash permanently deletes todos that have been in the trash for longer
+// than retention and reports how many were removed.
+func (s *TodoService) PurgeTrash(retention time.Duration) (int64, error) {
+	purged, err := s.repo.PurgeDeletedBefore(time.Now().Add(-retention))
+	if err != nil {
+		return purged, err
+	}
+
+	s.removeOrphans()
+	return purged, nil
+}
+
+// removeOrphans removes the attachments of purged todos. The todos are gone
+// either way, so failures are not reported; the next purge retries them.
+func (s *TodoService) removeOrphans() {
+	if s.attachments != nil {
+		s.attachments.RemoveOrphans()
+	}
+}
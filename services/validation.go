@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"example/Studying/models"
+	"example/Studying/recurrence"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidPriority = errors.New("Priority have to be one of none, low, medium, high, urgent")
+	ErrInvalidState    = errors.New("State have to be one of " + strings.Join(models.StateNames(), ", "))
+)
+
+// ValidationError reports a todo field that does not satisfy the rules
+// enforced on create, replace and patch.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// validateFields checks what cannot be represented on a todo at all, before
+// the fields are applied to one.
+func validateFields(fields TodoFields) error {
+	if _, ok := models.ParsePriority(fields.Priority); !ok {
+		return &ValidationError{Field: "priority", Message: ErrInvalidPriority.Error()}
+	}
+	if _, ok := models.ParseState(fields.State); !ok && fields.State != "" {
+		return &ValidationError{Field: "state", Message: ErrInvalidState.Error()}
+	}
+	if fields.RRule != "" {
+		if _, err := recurrence.Parse(fields.RRule); err != nil {
+			return &ValidationError{Field: "rrule", Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+func validateTodo(todo *models.ToDo) error {
+	if utf8.RuneCountInString(todo.Title) < 3 {
+		return &ValidationError{Field: "title", Message: "Title have to be more than 3 letters"}
+	}
+
+	if todo.StartAt != nil && todo.DueAt != nil && todo.StartAt.After(*todo.DueAt) {
+		return &ValidationError{Field: "start_at", Message: "Start date have to be before due date"}
+	}
+
+	if todo.RRule != "" && todo.DueAt == nil {
+		return &ValidationError{Field: "due_at", Message: "Recurring todo have to have a due date"}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"example/Studying/models"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Workflow decides which states a todo may move between. New todos start in
+// Initial, and a todo may always stay in the state it is in.
+type Workflow struct {
+	Initial     models.State
+	Transitions map[models.State][]models.State
+}
+
+// TransitionError is returned for a state change the workflow does not allow.
+type TransitionError struct {
+	From models.State
+	To   models.State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("Todo can't move from %s to %s", e.From, e.To)
+}
+
+// DefaultWorkflow is the workflow used unless WORKFLOW_TRANSITIONS says
+// otherwise.
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Initial: models.StateBacklog,
+		Transitions: map[models.State][]models.State{
+			models.StateBacklog:    {models.StateInProgress, models.StateBlocked, models.StateDone, models.StateCancelled},
+			models.StateInProgress: {models.StateBacklog, models.StateBlocked, models.StateInReview, models.StateDone, models.StateCancelled},
+			models.StateBlocked:    {models.StateBacklog, models.StateInProgress, models.StateCancelled},
+			models.StateInReview:   {models.StateInProgress, models.StateDone, models.StateCancelled},
+			models.StateDone:       {models.StateBacklog, models.StateInProgress},
+			models.StateCancelled:  {models.StateBacklog},
+		},
+	}
+}
+
+// ParseWorkflow reads a workflow written as semicolon separated rules
+// "from>to,to", e.g. "backlog>in_progress,done;in_progress>done;done>backlog".
+// The first state mentioned is the initial one. States without a rule can't
+// be left.
+func ParseWorkflow(spec string) (*Workflow, error) {
+	workflow := &Workflow{Transitions: map[models.State][]models.State{}}
+
+	for _, rule := range strings.Split(spec, ";") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+
+		from, targets, ok := strings.Cut(rule, ">")
+		if !ok {
+			return nil, fmt.Errorf("Invalid workflow rule %q", rule)
+		}
+
+		state, err := parseWorkflowState(from)
+		if err != nil {
+			return nil, err
+		}
+		if workflow.Initial == "" {
+			workflow.Initial = state
+		}
+
+		for _, name := range strings.Split(targets, ",") {
+			target, err := parseWorkflowState(name)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.Contains(workflow.Transitions[state], target) {
+				workflow.Transitions[state] = append(workflow.Transitions[state], target)
+			}
+		}
+	}
+
+	if workflow.Initial == "" {
+		return nil, fmt.Errorf("Workflow has no rules")
+	}
+
+	return workflow, nil
+}
+
+func parseWorkflowState(name string) (models.State, error) {
+	state, ok := models.ParseState(strings.TrimSpace(name))
+	if !ok {
+		return "", fmt.Errorf("Unknown state %q", strings.TrimSpace(name))
+	}
+
+	return state, nil
+}
+
+// Allows reports whether a todo in state from may move to state to.
+func (w *Workflow) Allows(from models.State, to models.State) bool {
+	return from == to || slices.Contains(w.Transitions[from], to)
+}
+
+// resolveState works out the state fields ask for when they replace the
+// fields of old, a nil old meaning a new todo. An explicit state wins;
+// otherwise flipping the older status flag completes the todo or reopens it.
+func (w *Workflow) resolveState(old *models.ToDo, fields TodoFields) models.State {
+	if fields.State != "" && (old == nil || models.State(fields.State) != old.State) {
+		return models.State(fields.State)
+	}
+
+	switch {
+	case old == nil && fields.Status:
+		return models.StateDone
+	case old == nil:
+		return w.Initial
+	case fields.Status && !old.Status:
+		return models.StateDone
+	case !fields.Status && old.Status:
+		return w.Initial
+	}
+
+	return old.State
+}
+
+// transition moves todo, an edited copy of old, to the state fields ask
+// for, if the workflow allows it.
+func (w *Workflow) transition(old *models.ToDo, todo *models.ToDo, fields TodoFields) error {
+	state := w.resolveState(old, fields)
+	if old != nil && !w.Allows(old.State, state) {
+		return &TransitionError{From: old.State, To: state}
+	}
+
+	setState(todo, state, time.Now())
+	return nil
+}
+
+// setState puts todo in state, keeping Status and CompletedAt in step.
+func setState(todo *models.ToDo, state models.State, now time.Time) {
+	if state == models.StateDone && todo.State != models.StateDone {
+		completed := now.UTC()
+		todo.CompletedAt = &completed
+	}
+	if state != models.StateDone {
+		todo.CompletedAt = nil
+	}
+
+	todo.State = state
+	todo.Status = state == models.StateDone
+}
@@ -0,0 +1,76 @@
+// Package storage keeps the contents of attachments outside the database,
+// behind the BlobStore interface. LocalStore writes files to a directory and
+// S3Store talks to any S3-compatible object store.
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrNotFound means no blob is stored under the key.
+	ErrNotFound = errors.New("storage: blob not found")
+	// ErrInvalidKey means the key is empty or escapes the store, e.g. "../x".
+	ErrInvalidKey = errors.New("storage: invalid key")
+	// ErrSizeMismatch means the content was shorter or longer than declared.
+	ErrSizeMismatch = errors.New("storage: content does not match its size")
+)
+
+// BlobStore stores opaque blobs under slash separated keys such as
+// "todos/7/3f2a9c". Blobs are written once and never changed in place.
+type BlobStore interface {
+	// Put stores exactly size bytes read from content under key.
+	Put(key string, content io.Reader, size int64, contentType string) error
+	// Get opens the blob under key for streaming; the caller closes it.
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing blob is not an
+	// error.
+	Delete(key string) error
+}
+
+// checkKey accepts clean relative keys only.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+// exactReader reads exactly size bytes from r, failing with ErrSizeMismatch
+// if r ends early or has more.
+type exactReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func exactly(r io.Reader, size int64) io.Reader {
+	return &exactReader{r: r, remaining: size}
+}
+
+func (e *exactReader) Read(p []byte) (int, error) {
+	if e.remaining <= 0 {
+		var probe [1]byte
+		if n, _ := io.ReadFull(e.r, probe[:]); n > 0 {
+			return 0, ErrSizeMismatch
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > e.remaining {
+		p = p[:e.remaining]
+	}
+	n, err := e.r.Read(p)
+	e.remaining -= int64(n)
+	if err == io.EOF && e.remaining > 0 {
+		return n, ErrSizeMismatch
+	}
+	if err == io.EOF {
+		err = nil
+	}
+
+	return n, err
+}
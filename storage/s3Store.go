@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Config locates a bucket on an S3-compatible service. Endpoint is the
+// base URL, e.g. "https://s3.eu-central-1.amazonaws.com" or
+// "http://localhost:9000" for MinIO; buckets are addressed path-style.
+type S3Config struct {
+	Endpoint    string
+	Region      string
+	Bucket      string
+	Credentials Credentials
+}
+
+// S3Store keeps blobs as objects of one bucket, streaming them both ways.
+type S3Store struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3Store(config S3Config) *S3Store {
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return &S3Store{
+		config: config,
+		// No overall timeout: it would cut off long downloads mid-stream.
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: time.Minute,
+		}},
+	}
+}
+
+func (s *S3Store) Put(key string, content io.Reader, size int64, contentType string) error {
+	req, err := s.request(http.MethodPut, key, exactly(content, size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, UnsignedPayload)
+	if err != nil {
+		// The object may have been stored if the content turned out to be
+		// too long only after the declared size was sent.
+		s.Delete(key)
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) Get(key string) (io.ReadCloser, error) {
+	req, err := s.request(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, EmptyPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(key string) error {
+	req, err := s.request(http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, EmptyPayload)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) request(method string, key string, body io.Reader) (*http.Request, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest(method, s.config.Endpoint+"/"+awsEscape(s.config.Bucket, true)+"/"+awsEscape(key, false), body)
+}
+
+// do signs and sends req, turning error statuses into errors.
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	SignV4(req, s.config.Credentials, s.config.Region, "s3", payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return nil, fmt.Errorf("storage: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+}
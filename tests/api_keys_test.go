@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type createdAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+func TestAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/api-keys", controllers.APIKeyIndex)
+	r.POST("/api-keys", controllers.APIKeyCreate)
+	r.DELETE("/api-keys/:id", controllers.APIKeyDelete)
+	r.GET("/todos", controllers.ToDoIndex)
+	r.POST("/todos", controllers.ToDoCreate)
+	r.DELETE("/todos/:id", controllers.ToDoDelete)
+
+	// Validation
+	for _, payload := range []string{
+		`{"name": "ci"}`,
+		`{"name": "  ", "scopes": ["read"]}`,
+		`{"name": "ci", "scopes": []}`,
+		`{"name": "ci", "scopes": ["admin"]}`,
+		`{"name": "ci", "scopes": ["read"], "expires_at": "2000-01-01T00:00:00Z"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, sendJSON(r, "POST", "/api-keys", "application/json", payload).Code, payload)
+	}
+
+	// The key is shown once and only its prefix is listed
+	w := sendJSON(r, "POST", "/api-keys", "application/json", `{"name": " reports ", "scopes": ["read", "read"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	reader := decode[map[string]createdAPIKey](t, w)["api_key"]
+	assert.True(t, strings.HasPrefix(reader.Key, "tdk_"))
+	assert.Equal(t, reader.Key[:12], reader.Prefix)
+	assert.Equal(t, "reports", reader.Name)
+	assert.Equal(t, []models.Scope{models.ScopeRead}, reader.Scopes)
+	assert.Nil(t, reader.ExpiresAt)
+	assert.Nil(t, reader.LastUsedAt)
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	w = sendJSON(r, "POST", "/api-keys", "application/json", fmt.Sprintf(`{"name": "sync", "scopes": ["read", "write"], "expires_at": %q}`, expires.Format(time.RFC3339)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	writer := decode[map[string]createdAPIKey](t, w)["api_key"]
+	if assert.NotNil(t, writer.ExpiresAt) {
+		assert.True(t, expires.Equal(*writer.ExpiresAt))
+	}
+
+	w = sendJSON(r, "GET", "/api-keys", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), reader.Key)
+	assert.NotContains(t, w.Body.String(), writer.Key)
+	var listed map[string][]models.APIKey
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, []uint{writer.ID, reader.ID}, []uint{listed["api_keys"][0].ID, listed["api_keys"][1].ID})
+
+	// Keys act as their user, within their scopes
+	asKey := func(key, method, target, payload string) *httptest.ResponseRecorder {
+		return sendWithHeader(r, method, target, payload, "Authorization", "Bearer "+key)
+	}
+	assert.Equal(t, http.StatusOK, asKey(reader.Key, "GET", "/todos", "").Code)
+	w = asKey(reader.Key, "POST", "/todos", `{"title": "From a Script"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error": "API key doesn't have the write scope"}`, w.Body.String())
+
+	w = asKey(writer.Key, "POST", "/todos", `{"title": "From a Script"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decode[map[string]models.ToDo](t, w)["todo"]
+	assert.Equal(t, testUser.ID, created.OwnerID)
+	assert.Equal(t, http.StatusNoContent, asKey(writer.Key, "DELETE", fmt.Sprintf("/todos/%d", created.ID), "").Code)
+	defer initializers.TodoRepository.Purge(created.ID, 0)
+
+	w = sendJSON(r, "GET", "/api-keys", "", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	for _, key := range listed["api_keys"] {
+		assert.NotNil(t, key.LastUsedAt, key.Name)
+	}
+
+	// Keys can't manage keys
+	assert.Equal(t, http.StatusForbidden, asKey(writer.Key, "GET", "/api-keys", "").Code)
+	assert.Equal(t, http.StatusForbidden, asKey(writer.Key, "POST", "/api-keys", `{"name": "more", "scopes": ["write"]}`).Code)
+
+	// Unknown, expired and revoked keys are rejected
+	w = asKey("tdk_unknown", "GET", "/todos", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error": "Invalid API key"}`, w.Body.String())
+
+	past := time.Now().Add(-time.Minute)
+	hash := sha256.Sum256([]byte("tdk_expired"))
+	expired := models.APIKey{UserID: testUser.ID, Name: "old", Prefix: "tdk_expired", KeyHash: hex.EncodeToString(hash[:]), Scopes: []models.Scope{models.ScopeRead}, ExpiresAt: &past}
+	assert.NoError(t, initializers.UserRepository.CreateAPIKey(&expired))
+	assert.Equal(t, http.StatusUnauthorized, asKey("tdk_expired", "GET", "/todos", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, sendJSON(r, "DELETE", fmt.Sprintf("/api-keys/%d", reader.ID), "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, asKey(reader.Key, "GET", "/todos", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "DELETE", fmt.Sprintf("/api-keys/%d", reader.ID), "", "").Code)
+
+	// Somebody else's keys can't be revoked
+	_, token, err := logIn("keyholder")
+	if assert.NoError(t, err) {
+		w = sendWithHeader(r, "DELETE", fmt.Sprintf("/api-keys/%d", writer.ID), "", "Authorization", "Bearer "+token)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, asKey(writer.Key, "GET", "/todos", "").Code)
+	}
+	sendJSON(r, "DELETE", fmt.Sprintf("/api-keys/%d", writer.ID), "", "")
+	initializers.UserRepository.DeleteAPIKey(testUser.ID, expired.ID)
+}
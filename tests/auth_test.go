@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/jwt"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenResponse struct {
+	AccessToken  string      `json:"access_token"`
+	TokenType    string      `json:"token_type"`
+	RefreshToken string      `json:"refresh_token"`
+	User         models.User `json:"user"`
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.POST("/auth/register", controllers.AuthRegister)
+	r.POST("/auth/login", controllers.AuthLogin)
+	r.POST("/auth/refresh", controllers.AuthRefresh)
+	r.POST("/auth/logout", controllers.AuthLogout)
+	r.GET("/todos", controllers.Authenticate, controllers.ToDoIndex)
+
+	// Registration
+	w := sendJSON(r, "POST", "/auth/register", "application/json", `{"username": " Alice ", "email": "Alice@Example.com", "password": "correct horse"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var registered map[string]models.User
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+	assert.Equal(t, "alice", registered["user"].Username)
+	assert.Equal(t, "alice@example.com", registered["user"].Email)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	for _, payload := range []string{
+		`{"username": "alice", "email": "other@example.com", "password": "correct horse"}`,
+		`{"username": "other", "email": "ALICE@example.com", "password": "correct horse"}`,
+	} {
+		assert.Equal(t, http.StatusConflict, sendJSON(r, "POST", "/auth/register", "application/json", payload).Code, payload)
+	}
+	for _, payload := range []string{
+		`{"username": "bob", "email": "bob@example.com"}`,
+		`{"username": "bo", "email": "bob@example.com", "password": "correct horse"}`,
+		`{"username": "bob smith", "email": "bob@example.com", "password": "correct horse"}`,
+		`{"username": "bob", "email": "not an email", "password": "correct horse"}`,
+		`{"username": "bob", "email": "bob@example.com", "password": "short"}`,
+		fmt.Sprintf(`{"username": "bob", "email": "bob@example.com", "password": %q}`, strings.Repeat("a", 73)),
+	} {
+		assert.Equal(t, http.StatusBadRequest, sendJSON(r, "POST", "/auth/register", "application/json", payload).Code, payload)
+	}
+
+	// Login by username or email
+	var tokens tokenResponse
+	for _, login := range []string{"alice", "ALICE@example.com"} {
+		w = sendJSON(r, "POST", "/auth/login", "application/json", fmt.Sprintf(`{"login": %q, "password": "correct horse"}`, login))
+		assert.Equal(t, http.StatusOK, w.Code, login)
+		tokens = decode[tokenResponse](t, w)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, "alice", tokens.User.Username)
+	}
+	for _, payload := range []string{
+		`{"login": "alice", "password": "wrong horse"}`,
+		`{"login": "nobody", "password": "correct horse"}`,
+	} {
+		w = sendJSON(r, "POST", "/auth/login", "application/json", payload)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, payload)
+		assert.JSONEq(t, `{"error": "Invalid login or password"}`, w.Body.String())
+	}
+
+	// Only a valid access token gets through
+	assert.Equal(t, http.StatusOK, sendWithHeader(r, "GET", "/todos", "", "Authorization", "Bearer "+tokens.AccessToken).Code)
+
+	expired, _ := initializers.Auth.Signer.Sign(jwt.Claims{Subject: fmt.Sprint(tokens.User.ID), ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	forged, _ := jwt.NewHS256Signer([]byte("some other secret, long enough!!")).Sign(jwt.Claims{Subject: fmt.Sprint(tokens.User.ID), ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	for header, message := range map[string]string{
+		"":                              "Authentication required",
+		"Bearer":                        "Authentication required",
+		"Basic " + tokens.AccessToken:   "Authentication required",
+		"Bearer nope":                   "Invalid access token",
+		"Bearer " + forged:              "Invalid access token",
+		"Bearer " + tokens.RefreshToken: "Invalid access token",
+		"Bearer " + expired:             "Access token expired",
+	} {
+		w = sendWithHeader(r, "GET", "/todos", "", "Authorization", header)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+		assert.JSONEq(t, fmt.Sprintf(`{"error": %q}`, message), w.Body.String(), header)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer", header)
+	}
+
+	// Refresh tokens work once
+	w = sendJSON(r, "POST", "/auth/refresh", "application/json", fmt.Sprintf(`{"refresh_token": %q}`, tokens.RefreshToken))
+	assert.Equal(t, http.StatusOK, w.Code)
+	refreshed := decode[tokenResponse](t, w)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, "alice", refreshed.User.Username)
+	assert.Equal(t, http.StatusOK, sendWithHeader(r, "GET", "/todos", "", "Authorization", "Bearer "+refreshed.AccessToken).Code)
+
+	w = sendJSON(r, "POST", "/auth/refresh", "application/json", fmt.Sprintf(`{"refresh_token": %q}`, tokens.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error": "Invalid refresh token"}`, w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, "POST", "/auth/refresh", "application/json", `{}`).Code)
+
+	// Logging out revokes the session
+	assert.Equal(t, http.StatusNoContent, sendJSON(r, "POST", "/auth/logout", "application/json", fmt.Sprintf(`{"refresh_token": %q}`, refreshed.RefreshToken)).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(r, "POST", "/auth/refresh", "application/json", fmt.Sprintf(`{"refresh_token": %q}`, refreshed.RefreshToken)).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(r, "POST", "/auth/logout", "application/json", fmt.Sprintf(`{"refresh_token": %q}`, refreshed.RefreshToken)).Code)
+}
+
+func TestTodoOwnership(t *testing.T) {
+	other, token, err := logIn("intruder")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	mine := models.ToDo{Title: "Private Title"}
+	if err := ownTodos().Create(&mine); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+	defer initializers.TodoRepository.Purge(mine.ID, 0)
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos", controllers.ToDoCreate)
+	r.GET("/todos", controllers.ToDoIndex)
+	r.GET("/todos/:id", controllers.ToDoShow)
+	r.PUT("/todos/:id", controllers.ToDoUpdate)
+	r.DELETE("/todos/:id", controllers.ToDoDelete)
+	r.GET("/todos/:id/comments", controllers.CommentIndex)
+	r.PUT("/todos/:id/tags/:tagId", controllers.ToDoAttachTag)
+	r.GET("/tags", controllers.TagIndex)
+	r.POST("/tags", controllers.TagCreate)
+	r.GET("/tags/:id", controllers.TagShow)
+	r.DELETE("/tags/:id", controllers.TagDelete)
+
+	asOther := func(method, target, payload string) int {
+		return sendWithHeader(r, method, target, payload, "Authorization", "Bearer "+token).Code
+	}
+	target := fmt.Sprintf("/todos/%d", mine.ID)
+
+	// Somebody else's todos do not exist for the caller
+	assert.Equal(t, http.StatusNotFound, asOther("GET", target, ""))
+	assert.Equal(t, http.StatusNotFound, asOther("PUT", target, `{"title": "Taken Over", "body": "", "status": true}`))
+	assert.Equal(t, http.StatusNotFound, asOther("DELETE", target, ""))
+	assert.Equal(t, http.StatusNotFound, asOther("GET", target+"/comments", ""))
+
+	tag := models.Tag{Name: "ownership", OwnerID: testUser.ID}
+	if err := initializers.TagRepository.Create(&tag); err != nil {
+		t.Fatalf("Failed to initialize test tag: %s", err)
+	}
+	defer initializers.TagRepository.Delete(tag.ID)
+	assert.Equal(t, http.StatusNotFound, asOther("PUT", fmt.Sprintf("%s/tags/%d", target, tag.ID), ""))
+	assert.Empty(t, decode[map[string]models.ToDo](t, sendJSON(r, "GET", target, "", ""))["todo"].Tags)
+
+	// Tags are per user: names may repeat and tags of others do not exist
+	w := sendWithHeader(r, "POST", "/tags", `{"name": "ownership"}`, "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	theirs := decode[map[string]models.Tag](t, w)["tag"]
+	defer initializers.TagRepository.Delete(theirs.ID)
+	assert.Equal(t, other.ID, theirs.OwnerID)
+	assert.Equal(t, http.StatusNotFound, asOther("GET", fmt.Sprintf("/tags/%d", tag.ID), ""))
+	assert.Equal(t, http.StatusNotFound, asOther("DELETE", fmt.Sprintf("/tags/%d", tag.ID), ""))
+	w = sendWithHeader(r, "GET", "/tags", "", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tags map[string][]models.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tags))
+	if assert.Len(t, tags["tags"], 1) {
+		assert.Equal(t, theirs.ID, tags["tags"][0].ID)
+	}
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "PUT", fmt.Sprintf("%s/tags/%d", target, theirs.ID), "", "").Code)
+
+	// New todos belong to their creator
+	w = sendWithHeader(r, "POST", "/todos", `{"title": "Intruder Title"}`, "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decode[map[string]models.ToDo](t, w)["todo"]
+	defer initializers.TodoRepository.Purge(created.ID, 0)
+	assert.Equal(t, other.ID, created.OwnerID)
+
+	listed := func(token string) []uint {
+		w := sendWithHeader(r, "GET", "/todos?limit=100", "", "Authorization", "Bearer "+token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var page pageResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return todoIDs(page.Todos)
+	}
+	assert.Equal(t, []uint{created.ID}, listed(token))
+	assert.Contains(t, listed(testToken), mine.ID)
+	assert.NotContains(t, listed(testToken), created.ID)
+
+	assert.Equal(t, http.StatusOK, sendJSON(r, "GET", target, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", fmt.Sprintf("/todos/%d", created.ID), "", "").Code)
+}
+
+func TestMigrateOwners(t *testing.T) {
+	if DB == nil {
+		t.Skip("Migrations only run on a database")
+	}
+
+	project := models.Project{Name: "Legacy project"}
+	if err := initializers.ProjectRepository.Create(&project); err != nil {
+		t.Fatalf("Failed to initialize test project: %s", err)
+	}
+	defer initializers.ProjectRepository.Delete(project.ID, false)
+	legacy := models.ToDo{Title: "Legacy todo", ProjectID: &project.ID}
+	if err := initializers.TodoRepository.Create(&legacy); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+	defer initializers.TodoRepository.Purge(legacy.ID, 0)
+
+	kept := models.Tag{Name: "merged", OwnerID: testUser.ID}
+	merged := models.Tag{Name: "merged"}
+	moved := models.Tag{Name: "legacy"}
+	for _, tag := range []*models.Tag{&kept, &merged, &moved} {
+		if err := initializers.TagRepository.Create(tag); err != nil {
+			t.Fatalf("Failed to initialize test tag: %s", err)
+		}
+		defer initializers.TagRepository.Delete(tag.ID)
+		assert.NoError(t, initializers.TagRepository.Attach(legacy.ID, tag.ID))
+	}
+
+	// Legacy rows go to the first admin, tags with a name they use included
+	assert.NoError(t, initializers.MigrateDB())
+
+	found, err := ownTodos().FindByID(legacy.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"legacy", "merged"}, tagNames(*found))
+		assert.Equal(t, kept.ID, found.Tags[1].ID)
+	}
+	owned, err := repositories.NewOwnedProjectRepository(initializers.ProjectRepository, testUser.ID).FindByID(project.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, testUser.ID, owned.OwnerID)
+	}
+	tag, err := initializers.TagRepository.FindByID(moved.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, testUser.ID, tag.OwnerID)
+	}
+	_, err = initializers.TagRepository.FindByID(merged.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	// Migrated before anybody registered, legacy rows wait for the first
+	// admin
+	orphan := models.ToDo{Title: "Orphaned todo"}
+	if err := initializers.TodoRepository.Create(&orphan); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+	defer initializers.TodoRepository.Purge(orphan.ID, 0)
+	var admins []uint
+	assert.NoError(t, DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Pluck("id", &admins).Error)
+	assert.NoError(t, DB.Model(&models.User{}).Where("id IN ?", admins).Update("role", models.RoleEditor).Error)
+	defer DB.Model(&models.User{}).Where("id IN ?", admins).Update("role", models.RoleAdmin)
+
+	founder, _, err := logIn("founder")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	defer DB.Model(founder).Update("role", models.RoleEditor)
+	assert.Equal(t, models.RoleAdmin, founder.Role)
+	claimed, err := initializers.TodoRepository.FindByID(orphan.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, founder.ID, claimed.OwnerID)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type commentPage struct {
+	Comments []models.Comment `json:"comments"`
+	Total    int64            `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+func TestComments(t *testing.T) {
+	todo := models.ToDo{Title: "Discussed Title"}
+	other := models.ToDo{Title: "Other Discussion"}
+	for _, todo := range []*models.ToDo{&todo, &other} {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+	defer initializers.TodoRepository.Purge(other.ID, 0)
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+	r.GET("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoShow(c)
+	})
+	r.GET("/todos/:id/comments", func(c *gin.Context) {
+		controllers.CommentIndex(c)
+	})
+	r.POST("/todos/:id/comments", func(c *gin.Context) {
+		controllers.CommentCreate(c)
+	})
+	r.GET("/todos/:id/comments/:commentId", func(c *gin.Context) {
+		controllers.CommentShow(c)
+	})
+	r.PUT("/todos/:id/comments/:commentId", func(c *gin.Context) {
+		controllers.CommentUpdate(c)
+	})
+	r.DELETE("/todos/:id/comments/:commentId", func(c *gin.Context) {
+		controllers.CommentDelete(c)
+	})
+
+	target := fmt.Sprintf("/todos/%d/comments", todo.ID)
+	list := func(query string) commentPage {
+		w := sendJSON(r, "GET", target+query, "", "")
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		var page commentPage
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+
+	assert.Empty(t, list("").Comments)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", "/todos/99999/comments", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "POST", "/todos/99999/comments", "application/json", `{"author": "ann", "body": "Hi"}`).Code)
+
+	// Validation
+	w := sendJSON(r, "POST", target, "application/json", `{"author": "ann"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(r, "POST", target, "application/json", `{"author": "ann", "body": "   "}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(r, "POST", target, "application/json", fmt.Sprintf(`{"author": "ann", "body": %q}`, strings.Repeat("a", 10001)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Creating a thread; the author is the caller, whatever the body says
+	bodies := []string{"First *draft* is ready", "Looks good", "Merged"}
+	ids := []uint{}
+	for _, body := range bodies {
+		w = sendJSON(r, "POST", target, "application/json", fmt.Sprintf(`{"author": " ann ", "body": %q}`, body))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		comment := decode[map[string]models.Comment](t, w)["comment"]
+		assert.Equal(t, testUser.Username, comment.Author)
+		assert.Equal(t, body, comment.Body)
+		assert.Equal(t, todo.ID, comment.TodoID)
+		ids = append(ids, comment.ID)
+	}
+
+	page := list("")
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, bodies, []string{page.Comments[0].Body, page.Comments[1].Body, page.Comments[2].Body})
+
+	page = list("?limit=1&offset=1")
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, 1, page.Limit)
+	if assert.Len(t, page.Comments, 1) {
+		assert.Equal(t, ids[1], page.Comments[0].ID)
+	}
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, "GET", target+"?limit=-1", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, "GET", target+"?cursor=abc", "", "").Code)
+
+	// Counts show up in listings and on the todo
+	w, todos := getPage(t, r, "/todos?limit=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	counts := map[uint]int64{}
+	for _, listed := range todos.Todos {
+		counts[listed.ID] = listed.CommentCount
+	}
+	assert.Equal(t, int64(3), counts[todo.ID])
+	assert.Equal(t, int64(0), counts[other.ID])
+	assert.Equal(t, int64(3), decode[map[string]models.ToDo](t, sendJSON(r, "GET", fmt.Sprintf("/todos/%d", todo.ID), "", ""))["todo"].CommentCount)
+
+	// Editing keeps the author
+	commentTarget := fmt.Sprintf("%s/%d", target, ids[1])
+	w = sendJSON(r, "PUT", commentTarget, "application/json", `{"author": "bob", "body": "Looks **great**"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	edited := decode[map[string]models.Comment](t, w)["comment"]
+	assert.Equal(t, testUser.Username, edited.Author)
+	assert.Equal(t, "Looks **great**", edited.Body)
+
+	w = sendJSON(r, "GET", commentTarget, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Looks **great**", decode[map[string]models.Comment](t, w)["comment"].Body)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, "PUT", commentTarget, "application/json", `{"body": ""}`).Code)
+
+	// Comments belong to their todo
+	otherTarget := fmt.Sprintf("/todos/%d/comments/%d", other.ID, ids[1])
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", otherTarget, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "DELETE", otherTarget, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", target+"/abc", "", "").Code)
+
+	// Deleting
+	assert.Equal(t, http.StatusNoContent, sendJSON(r, "DELETE", commentTarget, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", commentTarget, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "DELETE", commentTarget, "", "").Code)
+	assert.Equal(t, int64(2), list("").Total)
+}
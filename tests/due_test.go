@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDoCreateWithDates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos", func(c *gin.Context) {
+		controllers.ToDoCreate(c)
+	})
+
+	w := sendJSON(r, "POST", "/todos", "application/json",
+		`{"title": "Planned Title", "body": "Planned Body", "start_at": "2024-01-30T09:00:00+03:00", "due_at": "2024-01-31T18:00:00+03:00"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]models.ToDo
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.NotNil(t, response["todo"].DueAt) && assert.NotNil(t, response["todo"].StartAt) {
+		assert.True(t, response["todo"].DueAt.Equal(time.Date(2024, 1, 31, 15, 0, 0, 0, time.UTC)))
+		assert.True(t, response["todo"].StartAt.Equal(time.Date(2024, 1, 30, 6, 0, 0, 0, time.UTC)))
+	}
+	initializers.TodoRepository.Purge(response["todo"].ID, 0)
+
+	w = sendJSON(r, "POST", "/todos", "application/json",
+		`{"title": "Planned Title", "start_at": "2024-02-01T00:00:00Z", "due_at": "2024-01-31T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Start date have to be before due date")
+}
+
+func TestToDoIndexDue(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %s", err)
+	}
+	tokyoNow := now.In(tokyo)
+	tokyoToday := time.Date(tokyoNow.Year(), tokyoNow.Month(), tokyoNow.Day(), 0, 1, 0, 0, tokyo)
+
+	at := func(t time.Time) *time.Time { return &t }
+	overdue := models.ToDo{Title: "Overdue ToDo", DueAt: at(today.Add(-12 * time.Hour))}
+	done := models.ToDo{Title: "Done ToDo", Status: true, DueAt: at(today.Add(-12 * time.Hour))}
+	cancelled := models.ToDo{Title: "Cancelled ToDo", State: models.StateCancelled, DueAt: at(today.Add(-12 * time.Hour))}
+	inReview := models.ToDo{Title: "In Review ToDo", State: models.StateInReview, DueAt: at(today.Add(-12 * time.Hour))}
+	dueToday := models.ToDo{Title: "Due Today ToDo", DueAt: at(today.Add(12 * time.Hour))}
+	dueLater := models.ToDo{Title: "Due Later ToDo", DueAt: at(today.AddDate(0, 0, 8))}
+	dueTokyo := models.ToDo{Title: "Due In Tokyo ToDo", DueAt: at(tokyoToday.UTC())}
+	undated := models.ToDo{Title: "Undated ToDo"}
+
+	todos := []*models.ToDo{&overdue, &done, &cancelled, &inReview, &dueToday, &dueLater, &dueTokyo, &undated}
+	for _, todo := range todos {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	defer func() {
+		for _, todo := range todos {
+			initializers.TodoRepository.Purge(todo.ID, 0)
+		}
+	}()
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	list := func(query url.Values) []uint {
+		query.Set("limit", "100")
+		w, page := getPage(t, r, "/todos?"+query.Encode())
+		assert.Equal(t, http.StatusOK, w.Code, query.Encode())
+		return todoIDs(page.Todos)
+	}
+
+	ids := list(url.Values{"due": {"overdue"}})
+	assert.Contains(t, ids, overdue.ID)
+	assert.Contains(t, ids, inReview.ID)
+	assert.NotContains(t, ids, done.ID)
+	assert.NotContains(t, ids, cancelled.ID)
+	assert.NotContains(t, ids, dueLater.ID)
+	assert.NotContains(t, ids, undated.ID)
+
+	ids = list(url.Values{"due": {"today"}})
+	assert.Contains(t, ids, dueToday.ID)
+	assert.NotContains(t, ids, overdue.ID)
+	assert.NotContains(t, ids, dueLater.ID)
+
+	ids = list(url.Values{"due": {"week"}})
+	assert.Contains(t, ids, dueToday.ID)
+	assert.NotContains(t, ids, dueLater.ID)
+	assert.NotContains(t, ids, undated.ID)
+
+	ids = list(url.Values{"due": {"today"}, "tz": {"Asia/Tokyo"}})
+	assert.Contains(t, ids, dueTokyo.ID)
+
+	ids = list(url.Values{
+		"due_after":  {today.Format("2006-01-02")},
+		"due_before": {today.AddDate(0, 0, 1).Format(time.RFC3339)},
+	})
+	assert.Contains(t, ids, dueToday.ID)
+	assert.NotContains(t, ids, overdue.ID)
+	assert.NotContains(t, ids, dueLater.ID)
+
+	// A comparison with a missing due date is false, so only not matches it
+	ids = list(url.Values{"filter": {`due_at >= "2000-01-01"`}})
+	assert.Contains(t, ids, dueLater.ID)
+	assert.NotContains(t, ids, undated.ID)
+
+	ids = list(url.Values{"filter": {`not due_at < "2000-01-01"`}})
+	assert.Contains(t, ids, dueLater.ID)
+	assert.Contains(t, ids, undated.ID)
+
+	for _, query := range []string{"due=tomorrow", "due=today&tz=Mars/Olympus", "due_before=yesterday"} {
+		w, _ := getPage(t, r, "/todos?"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"example/Studying/controllers"
+	"example/Studying/filters"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchema = filters.Schema{
+	"id":         filters.Number,
+	"title":      filters.String,
+	"status":     filters.Bool,
+	"created_at": filters.Time,
+}
+
+func TestParseFilter(t *testing.T) {
+	node, err := filters.Parse(`title contains "report" AND (status = false or not created_at >= "2024-01-31")`, testSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, filters.And{
+		Left: filters.Comparison{Field: "title", Op: filters.Contains, Value: "report"},
+		Right: filters.Or{
+			Left: filters.Comparison{Field: "status", Op: filters.Eq, Value: false},
+			Right: filters.Not{Expr: filters.Comparison{
+				Field: "created_at",
+				Op:    filters.GreatEq,
+				Value: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+	}, node)
+
+	node, err = filters.Parse(`id > 3 and id <= 10 or title = "say \"hi\""`, testSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, filters.Or{
+		Left: filters.And{
+			Left:  filters.Comparison{Field: "id", Op: filters.Greater, Value: uint(3)},
+			Right: filters.Comparison{Field: "id", Op: filters.LessEq, Value: uint(10)},
+		},
+		Right: filters.Comparison{Field: "title", Op: filters.Eq, Value: `say "hi"`},
+	}, node)
+
+	for _, expr := range []string{
+		``,
+		`title`,
+		`title =`,
+		`title = report`,
+		`password = "x"`,
+		`status contains "t"`,
+		`status = 1`,
+		`id = -1`,
+		`created_at > "yesterday"`,
+		`(status = true`,
+		`status = true)`,
+		`status = true and`,
+		`title = "unterminated`,
+		`title ! "x"`,
+		`title = "x"; DROP TABLE to_dos`,
+		strings.Repeat(`title = "x" or `, 100) + `title = "x"`,
+	} {
+		_, err := filters.Parse(expr, testSchema)
+		var syntaxErr *filters.SyntaxError
+		assert.ErrorAs(t, err, &syntaxErr, expr)
+	}
+}
+
+func TestToDoIndexFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	target := "/todos?filter=" + url.QueryEscape(`title contains "todo 1" and status = true`)
+	w, response := getPage(t, r, target)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, response.Todos)
+	for _, todo := range response.Todos {
+		assert.Contains(t, strings.ToLower(todo.Title), "todo 1")
+		assert.True(t, todo.Status)
+	}
+
+	target = "/todos?status=false&filter=" + url.QueryEscape(`not (body contains "1" or body contains "2")`)
+	w, response = getPage(t, r, target)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, response.Todos)
+	for _, todo := range response.Todos {
+		assert.False(t, todo.Status)
+		assert.NotContains(t, todo.Body, "1")
+		assert.NotContains(t, todo.Body, "2")
+	}
+
+	// LIKE wildcards in the input are matched literally.
+	target = "/todos?filter=" + url.QueryEscape(`title contains "%" or title contains "_"`)
+	w, response = getPage(t, r, target)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, response.Todos)
+
+	// Case is ignored for every letter, not only ASCII ones.
+	cyrillic := models.ToDo{Title: "Купить МОЛОКО", Body: "Body"}
+	if err := ownTodos().Create(&cyrillic); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+	defer initializers.TodoRepository.Purge(cyrillic.ID, 0)
+	for _, word := range []string{"молоко", "КУПИТЬ"} {
+		target = "/todos?filter=" + url.QueryEscape(`title contains "`+word+`"`)
+		w, response = getPage(t, r, target)
+		assert.Equal(t, http.StatusOK, w.Code, word)
+		assert.Equal(t, []uint{cyrillic.ID}, todoIDs(response.Todos), word)
+	}
+
+	target = "/todos?filter=" + url.QueryEscape(`updated_at >= "2000-01-01" and created_at < "2999-01-01"`)
+	w, response = getPage(t, r, target)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, response.Todos)
+
+	target = "/todos?filter=" + url.QueryEscape(`title = `)
+	w, _ = getPage(t, r, target)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid filter")
+}
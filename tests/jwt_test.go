@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"example/Studying/jwt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWT(t *testing.T) {
+	now := time.Now()
+	claims := jwt.Claims{Subject: "7", Username: "ann", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+	secret := []byte("a secret that is long enough!!!!")
+
+	// HS256
+	token, err := jwt.NewHS256Signer(secret).Sign(claims)
+	assert.NoError(t, err)
+	verified, err := jwt.NewVerifier(secret).Verify(token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, claims, *verified)
+
+	_, err = jwt.NewVerifier(secret).Verify(token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, jwt.ErrExpired)
+	_, err = jwt.NewVerifier([]byte("another secret")).Verify(token, now)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	parts := strings.Split(token, ".")
+	tampered, _ := jwt.NewHS256Signer(secret).Sign(jwt.Claims{Subject: "8", ExpiresAt: claims.ExpiresAt})
+	for _, bad := range []string{
+		"", "a.b", parts[0] + "." + strings.Split(tampered, ".")[1] + "." + parts[2],
+		// alg "none" is never accepted
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".",
+	} {
+		_, err = jwt.NewVerifier(secret).Verify(bad, now)
+		assert.ErrorIs(t, err, jwt.ErrInvalidToken, bad)
+	}
+
+	// RS256 with keys looked up by kid
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	token, err = jwt.NewRS256Signer(key, "k1").Sign(claims)
+	assert.NoError(t, err)
+
+	verifier := jwt.NewVerifier(nil, jwt.StaticKeys{"k1": &key.PublicKey})
+	verified, err = verifier.Verify(token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, claims, *verified)
+
+	_, err = jwt.NewVerifier(nil, jwt.StaticKeys{"k2": &key.PublicKey}).Verify(token, now)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// An HS256 token keyed with the public key must not pass an RS256-only
+	// verifier.
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+	confused, _ := jwt.NewHS256Signer(public).Sign(claims)
+	_, err = verifier.Verify(confused, now)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// PEM private keys in both encodings
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pkcs8Bytes, _ := x509.MarshalPKCS8PrivateKey(key)
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes})
+	for _, data := range [][]byte{pkcs1, pkcs8} {
+		parsed, err := jwt.ParseRSAPrivateKey(data)
+		if assert.NoError(t, err) {
+			assert.True(t, key.Equal(parsed))
+		}
+	}
+	_, err = jwt.ParseRSAPrivateKey(public)
+	assert.Error(t, err)
+}
+
+func TestJWKSRotation(t *testing.T) {
+	now := time.Now()
+	claims := jwt.Claims{Subject: "7", ExpiresAt: now.Add(time.Minute).Unix()}
+
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS := func(keys jwt.StaticKeys, modTime time.Time) {
+		data, err := jwt.MarshalJWKS(keys)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, data, 0o600))
+		// Rewrites within the same clock tick would look unchanged.
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	writeJWKS(jwt.StaticKeys{"old": &oldKey.PublicKey}, now.Add(-time.Hour))
+	jwks, err := jwt.OpenJWKSFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	verifier := jwt.NewVerifier(nil, jwks)
+
+	oldToken, _ := jwt.NewRS256Signer(oldKey, "old").Sign(claims)
+	newToken, _ := jwt.NewRS256Signer(newKey, "new").Sign(claims)
+
+	_, err = verifier.Verify(oldToken, now)
+	assert.NoError(t, err)
+	_, err = verifier.Verify(newToken, now)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+
+	// Publishing the new key keeps old tokens working until the old key is
+	// dropped.
+	writeJWKS(jwt.StaticKeys{"old": &oldKey.PublicKey, "new": &newKey.PublicKey}, now.Add(-time.Minute))
+	_, err = verifier.Verify(newToken, now)
+	assert.NoError(t, err)
+	_, err = verifier.Verify(oldToken, now)
+	assert.NoError(t, err)
+
+	// Dropping the old key from the file revokes it right away
+	writeJWKS(jwt.StaticKeys{"new": &newKey.PublicKey}, now.Add(-time.Second))
+	_, err = verifier.Verify(oldToken, now)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+	_, err = verifier.Verify(newToken, now)
+	assert.NoError(t, err)
+
+	// A broken file keeps the keys that were read last.
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	unknown, _ := jwt.NewRS256Signer(newKey, "unknown").Sign(claims)
+	_, err = verifier.Verify(unknown, now)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+	_, err = verifier.Verify(newToken, now)
+	assert.NoError(t, err)
+
+	_, err = jwt.ParseJWKS([]byte(`{"keys": [{"kty": "RSA", "kid": "x", "n": "", "e": "AQAB"}]}`))
+	assert.Error(t, err)
+	keys, err := jwt.ParseJWKS([]byte(`{"keys": [{"kty": "EC", "kid": "ec"}, {"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`))
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
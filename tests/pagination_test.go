@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type pageResponse struct {
+	Todos      []models.ToDo `json:"todos"`
+	Total      int64         `json:"total"`
+	NextCursor *string       `json:"next_cursor"`
+	PrevCursor *string       `json:"prev_cursor"`
+}
+
+func getPage(t *testing.T, r *gin.Engine, target string) (*httptest.ResponseRecorder, pageResponse) {
+	req, _ := http.NewRequest("GET", target, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response pageResponse
+	if w.Code == http.StatusOK {
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+	}
+
+	return w, response
+}
+
+func TestToDoIndexCursorPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	// Walk forward through every page.
+	var seen []models.ToDo
+	var last pageResponse
+	target := "/todos?limit=3"
+	for target != "" {
+		w, response := getPage(t, r, target)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.LessOrEqual(t, len(response.Todos), 3)
+		seen = append(seen, response.Todos...)
+		last = response
+
+		target = ""
+		if response.NextCursor != nil {
+			assert.Contains(t, w.Header().Get("Link"), `rel="next"`)
+			target = "/todos?limit=3&cursor=" + url.QueryEscape(*response.NextCursor)
+		}
+	}
+
+	assert.Equal(t, last.Total, int64(len(seen)))
+	for i := 1; i < len(seen); i++ {
+		assert.Less(t, seen[i-1].ID, seen[i].ID)
+	}
+
+	// And back again from the last page.
+	var back []models.ToDo
+	response := last
+	for response.PrevCursor != nil {
+		var w *httptest.ResponseRecorder
+		w, response = getPage(t, r, "/todos?limit=3&cursor="+url.QueryEscape(*response.PrevCursor))
+		assert.Equal(t, http.StatusOK, w.Code)
+		back = append(response.Todos, back...)
+	}
+
+	assert.Equal(t, seen[:len(seen)-len(last.Todos)], back)
+}
+
+func TestToDoIndexOffsetPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	w, first := getPage(t, r, "/todos?status=true&limit=2&offset=0")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, first.Todos, 2)
+	assert.Contains(t, w.Header().Get("Link"), "offset=2")
+	assert.NotContains(t, w.Header().Get("Link"), `rel="prev"`)
+
+	w, second := getPage(t, r, "/todos?status=true&limit=2&offset=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, first.Total, second.Total)
+	assert.Contains(t, w.Header().Get("Link"), `rel="prev"`)
+	assert.True(t, strings.Contains(w.Header().Get("Link"), "status=true"))
+	for _, todo := range second.Todos {
+		assert.True(t, todo.Status)
+		assert.Greater(t, todo.ID, first.Todos[1].ID)
+	}
+}
+
+func TestToDoIndexWrongPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	for _, target := range []string{
+		"/todos?limit=abc",
+		"/todos?limit=-1",
+		"/todos?offset=-5",
+		"/todos?cursor=not-a-cursor",
+		"/todos?cursor=eyJhZnRlciI6MX0&offset=1",
+	} {
+		w, _ := getPage(t, r, target)
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDoPriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos", func(c *gin.Context) {
+		controllers.ToDoCreate(c)
+	})
+	r.PUT("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoUpdate(c)
+	})
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	w := sendJSON(r, "POST", "/todos", "application/json", `{"title": "Priority Title", "priority": "high"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"Priority":"high"`)
+
+	var response map[string]models.ToDo
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	todo := response["todo"]
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+	assert.Equal(t, models.PriorityHigh, todo.Priority)
+
+	w = sendJSON(r, "POST", "/todos", "application/json", `{"title": "Priority Title", "priority": "asap"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Priority have to be one of")
+
+	w = sendJSON(r, "PUT", fmt.Sprintf("/todos/%d", todo.ID), "application/json",
+		`{"title": "Priority Title", "body": "", "status": false, "priority": "someday"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	ids := func(query string) []uint {
+		w, page := getPage(t, r, "/todos?limit=100&"+query)
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		return todoIDs(page.Todos)
+	}
+	assert.Contains(t, ids("priority=urgent,high"), todo.ID)
+	assert.NotContains(t, ids("priority=none"), todo.ID)
+
+	w = sendJSON(r, "PUT", fmt.Sprintf("/todos/%d", todo.ID), "application/json",
+		`{"title": "Priority Title", "body": "", "status": false, "priority": "urgent"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, ids("priority=urgent"), todo.ID)
+	assert.NotContains(t, ids("priority=high"), todo.ID)
+
+	w, _ = getPage(t, r, "/todos?priority=high,")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestToDoIndexSmartOrder(t *testing.T) {
+	now := time.Now().UTC()
+	at := func(t time.Time) *time.Time { return &t }
+
+	urgent := models.ToDo{Title: "Smart Urgent", Priority: models.PriorityUrgent}
+	highLater := models.ToDo{Title: "Smart High Later", Priority: models.PriorityHigh, DueAt: at(now.Add(48 * time.Hour))}
+	highSoon := models.ToDo{Title: "Smart High Soon", Priority: models.PriorityHigh, DueAt: at(now.Add(time.Hour))}
+	highUndated := models.ToDo{Title: "Smart High Undated", Priority: models.PriorityHigh}
+	highUndatedToo := models.ToDo{Title: "Smart High Undated Too", Priority: models.PriorityHigh}
+	plain := models.ToDo{Title: "Smart Plain", DueAt: at(now.Add(-time.Hour))}
+
+	todos := []*models.ToDo{&plain, &highUndated, &highLater, &urgent, &highUndatedToo, &highSoon}
+	for _, todo := range todos {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	defer func() {
+		for _, todo := range todos {
+			initializers.TodoRepository.Purge(todo.ID, 0)
+		}
+	}()
+	expected := []uint{urgent.ID, highSoon.ID, highLater.ID, highUndated.ID, highUndatedToo.ID, plain.ID}
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	filter := url.QueryEscape(`title contains "Smart"`)
+
+	w, page := getPage(t, r, "/todos?filter="+filter)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, expected, todoIDs(page.Todos))
+
+	w, page = getPage(t, r, "/todos?sort=smart&filter="+filter)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, expected, todoIDs(page.Todos))
+
+	// Walk the same order two at a time, forwards and back
+	var walked []uint
+	target := "/todos?limit=2&filter=" + filter
+	for {
+		w, page = getPage(t, r, target)
+		assert.Equal(t, http.StatusOK, w.Code)
+		walked = append(walked, todoIDs(page.Todos)...)
+		if page.NextCursor == nil {
+			break
+		}
+		target = "/todos?limit=2&filter=" + filter + "&cursor=" + *page.NextCursor
+	}
+	assert.Equal(t, expected, walked)
+
+	w, page = getPage(t, r, "/todos?limit=2&filter="+filter+"&cursor="+*page.PrevCursor)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, expected[2:4], todoIDs(page.Todos))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func projectRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/projects", func(c *gin.Context) {
+		controllers.ProjectCreate(c)
+	})
+	r.GET("/projects", func(c *gin.Context) {
+		controllers.ProjectIndex(c)
+	})
+	r.GET("/projects/:id", func(c *gin.Context) {
+		controllers.ProjectShow(c)
+	})
+	r.PUT("/projects/:id", func(c *gin.Context) {
+		controllers.ProjectUpdate(c)
+	})
+	r.DELETE("/projects/:id", func(c *gin.Context) {
+		controllers.ProjectDelete(c)
+	})
+	r.GET("/projects/:id/todos", func(c *gin.Context) {
+		controllers.ProjectTodos(c)
+	})
+	r.POST("/todos", func(c *gin.Context) {
+		controllers.ToDoCreate(c)
+	})
+	r.PUT("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoUpdate(c)
+	})
+	r.PATCH("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoPatch(c)
+	})
+
+	return r
+}
+
+func TestProjects(t *testing.T) {
+	r := projectRouter()
+
+	w := sendJSON(r, "POST", "/projects", "application/json", `{"name": "  Work  ", "description": "Office"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	project := decode[map[string]models.Project](t, w)["project"]
+	assert.Equal(t, "Work", project.Name)
+	assert.Equal(t, "Office", project.Description)
+	defer initializers.ProjectRepository.Delete(project.ID, false)
+
+	w = sendJSON(r, "POST", "/projects", "application/json", `{"name": " "}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(r, "POST", "/projects", "application/json", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "GET", fmt.Sprintf("/projects/%d", project.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, project.ID, decode[map[string]models.Project](t, w)["project"].ID)
+
+	w = sendJSON(r, "PUT", fmt.Sprintf("/projects/%d", project.ID), "application/json", `{"name": "Job"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Job", decode[map[string]models.Project](t, w)["project"].Name)
+	assert.Equal(t, "", decode[map[string]models.Project](t, w)["project"].Description)
+
+	w = sendJSON(r, "GET", "/projects", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Projects []models.Project `json:"projects"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Projects, decode[map[string]models.Project](t, sendJSON(r, "GET", fmt.Sprintf("/projects/%d", project.ID), "", ""))["project"])
+
+	for _, target := range []string{"/projects/99999", "/projects/abc"} {
+		assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", target, "", "").Code)
+		assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", target+"/todos", "", "").Code)
+		assert.Equal(t, http.StatusNotFound, sendJSON(r, "DELETE", target, "", "").Code)
+	}
+}
+
+func TestProjectTodos(t *testing.T) {
+	r := projectRouter()
+
+	home := decode[map[string]models.Project](t, sendJSON(r, "POST", "/projects", "application/json", `{"name": "Home"}`))["project"]
+	garden := decode[map[string]models.Project](t, sendJSON(r, "POST", "/projects", "application/json", `{"name": "Garden"}`))["project"]
+	defer initializers.ProjectRepository.Delete(home.ID, false)
+	defer initializers.ProjectRepository.Delete(garden.ID, false)
+
+	create := func(payload string) models.ToDo {
+		w := sendJSON(r, "POST", "/todos", "application/json", payload)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		return decode[map[string]models.ToDo](t, w)["todo"]
+	}
+	dishes := create(fmt.Sprintf(`{"title": "Dishes", "project_id": %d}`, home.ID))
+	laundry := create(fmt.Sprintf(`{"title": "Laundry", "status": true, "project_id": %d}`, home.ID))
+	inbox := create(`{"title": "Inbox item"}`)
+	for _, todo := range []models.ToDo{dishes, laundry, inbox} {
+		defer initializers.TodoRepository.Purge(todo.ID, 0)
+	}
+	assert.Equal(t, home.ID, *dishes.ProjectID)
+	assert.Nil(t, inbox.ProjectID)
+
+	w := sendJSON(r, "POST", "/todos", "application/json", `{"title": "Nowhere", "project_id": 99999}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	list := func(target string) []uint {
+		w := sendJSON(r, "GET", target, "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var page struct {
+			Todos []models.ToDo `json:"todos"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return todoIDs(page.Todos)
+	}
+	assert.ElementsMatch(t, []uint{dishes.ID, laundry.ID}, list(fmt.Sprintf("/projects/%d/todos", home.ID)))
+	assert.Equal(t, []uint{laundry.ID}, list(fmt.Sprintf("/projects/%d/todos?status=true", home.ID)))
+	assert.Equal(t, []uint{dishes.ID}, list(fmt.Sprintf("/projects/%d/todos?filter=title%%20contains%%20%%22dish%%22", home.ID)))
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, "GET", fmt.Sprintf("/projects/%d/todos?priority=huge", home.ID), "", "").Code)
+
+	// Moving a todo between projects
+	w = sendJSON(r, "PATCH", fmt.Sprintf("/todos/%d", dishes.ID), "application/merge-patch+json", fmt.Sprintf(`{"project_id": %d}`, garden.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, garden.ID, *decode[map[string]models.ToDo](t, w)["todo"].ProjectID)
+	assert.Equal(t, []uint{dishes.ID}, list(fmt.Sprintf("/projects/%d/todos", garden.ID)))
+
+	w = sendJSON(r, "PATCH", fmt.Sprintf("/todos/%d", dishes.ID), "application/merge-patch+json", `{"project_id": 99999}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// PUT without project_id moves the todo to the inbox
+	w = sendJSON(r, "PUT", fmt.Sprintf("/todos/%d", dishes.ID), "application/json", `{"title": "Dishes", "body": "", "status": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, decode[map[string]models.ToDo](t, w)["todo"].ProjectID)
+	assert.Empty(t, list(fmt.Sprintf("/projects/%d/todos", garden.ID)))
+}
+
+func TestProjectDelete(t *testing.T) {
+	r := projectRouter()
+
+	kept := decode[map[string]models.Project](t, sendJSON(r, "POST", "/projects", "application/json", `{"name": "Kept todos"}`))["project"]
+	trashed := decode[map[string]models.Project](t, sendJSON(r, "POST", "/projects", "application/json", `{"name": "Trashed todos"}`))["project"]
+
+	create := func(project models.Project) models.ToDo {
+		todo := models.ToDo{Title: "Project todo", ProjectID: &project.ID}
+		if err := ownTodos().Create(&todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+		return todo
+	}
+	moved := create(kept)
+	cascaded := create(trashed)
+	alreadyTrashed := create(kept)
+	for _, todo := range []models.ToDo{moved, cascaded, alreadyTrashed} {
+		defer initializers.TodoRepository.Purge(todo.ID, 0)
+	}
+	assert.NoError(t, initializers.TodoRepository.Delete(alreadyTrashed.ID, 0))
+
+	assert.Equal(t, http.StatusBadRequest, sendJSON(r, "DELETE", fmt.Sprintf("/projects/%d?cascade=maybe", kept.ID), "", "").Code)
+
+	// By default the todos move to the inbox
+	assert.Equal(t, http.StatusNoContent, sendJSON(r, "DELETE", fmt.Sprintf("/projects/%d", kept.ID), "", "").Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", fmt.Sprintf("/projects/%d", kept.ID), "", "").Code)
+
+	todo, err := initializers.TodoRepository.FindByID(moved.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, todo.ProjectID)
+	assert.Equal(t, moved.Version+1, todo.Version)
+
+	todo, err = initializers.TodoRepository.FindByIDUnscoped(alreadyTrashed.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, todo.ProjectID)
+
+	// With cascade they go to the trash
+	assert.Equal(t, http.StatusNoContent, sendJSON(r, "DELETE", fmt.Sprintf("/projects/%d?cascade=true", trashed.ID), "", "").Code)
+
+	_, err = initializers.TodoRepository.FindByID(cascaded.ID)
+	assert.Error(t, err)
+	todo, err = initializers.TodoRepository.FindByIDUnscoped(cascaded.ID)
+	assert.NoError(t, err)
+	assert.True(t, todo.DeletedAt.Valid)
+	assert.Nil(t, todo.ProjectID)
+}
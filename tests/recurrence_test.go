@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/recurrence"
+	"example/Studying/services"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrenceRule(t *testing.T) {
+	start := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+	occurrences := func(text string, n int) []string {
+		rule, err := recurrence.Parse(text)
+		assert.NoError(t, err)
+
+		dates := []string{}
+		for at := start; len(dates) < n; {
+			next, ok := rule.Next(start, at)
+			if !ok {
+				break
+			}
+			dates = append(dates, next.Format("2006-01-02"))
+			at = next
+		}
+		return dates
+	}
+
+	assert.Equal(t, []string{"2024-02-01", "2024-02-02"}, occurrences("FREQ=DAILY;COUNT=3", 5))
+	assert.Equal(t, []string{"2024-02-02", "2024-02-05", "2024-02-09"}, occurrences("RRULE:FREQ=WEEKLY;BYDAY=MO,FR", 3))
+	assert.Equal(t, []string{"2024-03-31", "2024-05-31"}, occurrences("FREQ=MONTHLY", 2))
+	assert.Equal(t, []string{"2024-02-29", "2024-03-31", "2024-04-30"}, occurrences("FREQ=MONTHLY;BYMONTHDAY=-1", 3))
+	assert.Equal(t, []string{"2024-02-23", "2024-03-29", "2024-04-26"}, occurrences("FREQ=MONTHLY;BYDAY=-1FR;COUNT=4", 5))
+	assert.Equal(t, []string{"2024-02-14", "2024-02-28"}, occurrences("FREQ=WEEKLY;INTERVAL=2;UNTIL=20240301", 5))
+	assert.Equal(t, []string{"2024-02-29", "2028-02-29"}, occurrences("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", 2))
+	assert.Empty(t, occurrences("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", 1))
+
+	rule, err := recurrence.Parse("freq=weekly;byday=mo,we;interval=2")
+	assert.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", rule.String())
+
+	for _, text := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240301",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+	} {
+		_, err := recurrence.Parse(text)
+		assert.ErrorIs(t, err, recurrence.ErrInvalidRule, text)
+	}
+}
+
+type updatedTodo struct {
+	ToDo models.ToDo  `json:"todo"`
+	Next *models.ToDo `json:"next"`
+}
+
+func TestRecurringTodo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos", func(c *gin.Context) {
+		controllers.ToDoCreate(c)
+	})
+	r.PUT("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoUpdate(c)
+	})
+	r.PATCH("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoPatch(c)
+	})
+	r.GET("/series/:id", func(c *gin.Context) {
+		controllers.SeriesShow(c)
+	})
+
+	w := sendJSON(r, "POST", "/todos", "application/json", `{"title": "Weekly chores", "rrule": "FREQ=WEEKLY;BYDAY=MO"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(r, "POST", "/todos", "application/json", `{"title": "Weekly chores", "due_at": "2024-01-01T09:00:00Z", "rrule": "FREQ=SECONDLY"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "POST", "/todos", "application/json",
+		`{"title": "Weekly chores", "start_at": "2024-01-01T08:00:00Z", "due_at": "2024-01-01T09:00:00Z", "rrule": "rrule:freq=weekly;byday=mo"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	first := decode[map[string]models.ToDo](t, w)["todo"]
+	defer initializers.TodoRepository.Purge(first.ID, 0)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", first.RRule)
+	assert.NotNil(t, first.SeriesID)
+	target := fmt.Sprintf("/todos/%d", first.ID)
+
+	// Editing only this occurrence
+	w = sendJSON(r, "PATCH", target+"?scope=this", "application/merge-patch+json", `{"title": "Chores, but just once"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, decode[updatedTodo](t, w).Next)
+
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"rrule": "FREQ=DAILY"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(r, "PATCH", target+"?scope=all", "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Completing it creates the next occurrence from the series
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	second := decode[updatedTodo](t, w).Next
+	if !assert.NotNil(t, second) {
+		return
+	}
+	defer initializers.TodoRepository.Purge(second.ID, 0)
+	assert.Equal(t, "Weekly chores", second.Title)
+	assert.False(t, second.Status)
+	assert.Equal(t, first.SeriesID, second.SeriesID)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", second.RRule)
+	assert.Equal(t, time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), second.DueAt.UTC())
+	assert.Equal(t, time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC), second.StartAt.UTC())
+
+	// Only the latest occurrence creates another one
+	sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": false}`)
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, decode[updatedTodo](t, w).Next)
+
+	// Editing all future occurrences
+	target = fmt.Sprintf("/todos/%d", second.ID)
+	w = sendJSON(r, "PATCH", target+"?scope=future", "application/merge-patch+json",
+		`{"title": "Fortnightly chores", "rrule": "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sendJSON(r, "GET", fmt.Sprintf("/series/%d", *first.SeriesID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]models.Series
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Fortnightly chores", response["series"].Title)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", response["series"].RRule)
+	assert.Equal(t, second.ID, response["series"].LatestID)
+	assert.Equal(t, http.StatusNotFound, sendJSON(r, "GET", "/series/99999", "", "").Code)
+
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	third := decode[updatedTodo](t, w).Next
+	if !assert.NotNil(t, third) {
+		return
+	}
+	defer initializers.TodoRepository.Purge(third.ID, 0)
+	assert.Equal(t, "Fortnightly chores", third.Title)
+	assert.Equal(t, time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC), third.DueAt.UTC())
+
+	// Ending the series
+	target = fmt.Sprintf("/todos/%d", third.ID)
+	w = sendJSON(r, "PATCH", target+"?scope=future", "application/merge-patch+json", `{"rrule": null}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", decode[updatedTodo](t, w).ToDo.RRule)
+	assert.Equal(t, first.SeriesID, decode[updatedTodo](t, w).ToDo.SeriesID)
+
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, decode[updatedTodo](t, w).Next)
+}
+
+func TestUpdateTodoCreatesNextOccurrence(t *testing.T) {
+	todoService := services.NewTodoService(initializers.TodoRepository).WithSeries(initializers.SeriesRepository)
+
+	due := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	todo, err := todoService.CreateTodoFields(services.TodoFields{
+		Title:    "Monthly report",
+		Priority: "high",
+		DueAt:    &due,
+		RRule:    "FREQ=MONTHLY;COUNT=2",
+	})
+	assert.NoError(t, err)
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+
+	assert.NoError(t, todoService.UpdateTodo(todo, "Monthly report", "Sent", true))
+	assert.Equal(t, models.PriorityHigh, todo.Priority)
+
+	series, err := initializers.SeriesRepository.FindByID(*todo.SeriesID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, todo.ID, series.LatestID)
+
+	next, err := initializers.TodoRepository.FindByID(series.LatestID)
+	assert.NoError(t, err)
+	defer initializers.TodoRepository.Purge(next.ID, 0)
+	assert.Equal(t, "", next.Body)
+	assert.Equal(t, models.PriorityHigh, next.Priority)
+	assert.Equal(t, time.Date(2024, 4, 1, 18, 0, 0, 0, time.UTC), next.DueAt.UTC())
+
+	// COUNT=2 ends the series after the second occurrence
+	assert.NoError(t, todoService.UpdateTodo(next, next.Title, next.Body, true))
+	series, err = initializers.SeriesRepository.FindByID(*todo.SeriesID)
+	assert.NoError(t, err)
+	assert.Equal(t, next.ID, series.LatestID)
+}
+
+func TestSubtasksCreateNextOccurrence(t *testing.T) {
+	todoService := services.NewTodoService(initializers.TodoRepository).WithSeries(initializers.SeriesRepository)
+	subtaskService := services.NewSubtaskService(initializers.SubtaskRepository, todoService)
+
+	due := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	todo, err := todoService.CreateTodoFields(services.TodoFields{
+		Title: "Weekly review",
+		DueAt: &due,
+		RRule: "FREQ=WEEKLY",
+	})
+	assert.NoError(t, err)
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+
+	// Finishing the last subtask completes the todo like an update does
+	title, done := "Inbox zero", true
+	_, err = subtaskService.CreateSubtask(todo, services.SubtaskFields{Title: &title, Done: &done})
+	assert.NoError(t, err)
+
+	completed, err := initializers.TodoRepository.FindByID(todo.ID)
+	assert.NoError(t, err)
+	assert.True(t, completed.Status)
+
+	series, err := initializers.SeriesRepository.FindByID(*todo.SeriesID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, todo.ID, series.LatestID)
+
+	next, err := initializers.TodoRepository.FindByID(series.LatestID)
+	assert.NoError(t, err)
+	defer initializers.TodoRepository.Purge(next.ID, 0)
+	assert.Equal(t, time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC), next.DueAt.UTC())
+}
+
+func TestSharedRecurringTodo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	controllers.Routes(r)
+
+	_, partnerToken, err := logIn("partner")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	_, outsiderToken, err := logIn("outsider")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	w := sendAs(r, testToken, "POST", "/todo", `{"title": "Shared chores", "due_at": "2024-01-01T09:00:00Z", "rrule": "FREQ=WEEKLY"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	first := decode[map[string]models.ToDo](t, w)["todo"]
+	defer initializers.TodoRepository.Purge(first.ID, 0)
+	seriesURL := fmt.Sprintf("/series/%d", *first.SeriesID)
+	target := fmt.Sprintf("/todo/%d", first.ID)
+
+	// The series is as visible as its occurrences
+	assert.Equal(t, http.StatusNotFound, sendAs(r, partnerToken, "GET", seriesURL, "").Code)
+	assert.Equal(t, http.StatusCreated, sendAs(r, testToken, "POST", target+"/shares", `{"user": "partner", "role": "editor"}`).Code)
+	assert.Equal(t, http.StatusOK, sendAs(r, partnerToken, "GET", seriesURL, "").Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, outsiderToken, "GET", seriesURL, "").Code)
+
+	// The next occurrence is shared like the completed one
+	w = sendAs(r, partnerToken, "PATCH", target, `{"status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	next := decode[updatedTodo](t, w).Next
+	if !assert.NotNil(t, next) {
+		return
+	}
+	defer initializers.TodoRepository.Purge(next.ID, 0)
+	assert.Equal(t, testUser.ID, next.OwnerID)
+	nextURL := fmt.Sprintf("/todo/%d", next.ID)
+	assert.Equal(t, http.StatusOK, sendAs(r, partnerToken, "PATCH", nextURL, `{"title": "Chores changed by partner"}`).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, partnerToken, "DELETE", nextURL, "").Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, outsiderToken, "GET", nextURL, "").Code)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryTodoRepository(t *testing.T) {
+	todoService := services.NewTodoService(repositories.NewMemoryTodoRepository())
+
+	first, err := todoService.CreateTodo("First ToDo", "First Body", true)
+	assert.NoError(t, err)
+	second, err := todoService.CreateTodo("Second ToDo", "Second Body", false)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID+1, second.ID)
+
+	page, err := todoService.GetAllTodos(services.PageRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Todos, 2)
+
+	done, err := todoService.FindByStatus(true, services.PageRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, done.Todos, 1)
+	assert.Equal(t, first.ID, done.Todos[0].ID)
+
+	found, err := todoService.FindTodo(fmt.Sprintf("%d", second.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "Second ToDo", found.Title)
+
+	err = todoService.UpdateTodo(found, "Updated ToDo", "Updated Body", true)
+	assert.NoError(t, err)
+
+	updated, err := todoService.FindTodo(fmt.Sprintf("%d", second.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated ToDo", updated.Title)
+	assert.True(t, updated.Status)
+
+	err = todoService.DeleteTodo(fmt.Sprintf("%d", first.ID))
+	assert.NoError(t, err)
+
+	_, err = todoService.FindTodo(fmt.Sprintf("%d", first.ID))
+	assert.Error(t, err)
+
+	err = todoService.DeleteTodo(fmt.Sprintf("%d", first.ID))
+	assert.Error(t, err)
+
+	err = todoService.UpdateTodo(first, "Deleted ToDo", "Deleted Body", false)
+	assert.Error(t, err)
+
+	page, err = todoService.GetAllTodos(services.PageRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Todos, 1)
+
+	fmt.Println("TestMemoryTodoRepository passed!")
+}
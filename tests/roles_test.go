@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/jwt"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"example/Studying/storage"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// The untouchable repositories implement nothing: any call panics, which
+// gin turns into a 500, so a 403 shows the request never reached them.
+type untouchableTodos struct{ repositories.TodoRepository }
+type untouchableTags struct{ repositories.TagRepository }
+type untouchableSubtasks struct{ repositories.SubtaskRepository }
+type untouchableComments struct{ repositories.CommentRepository }
+type untouchableAttachments struct {
+	repositories.AttachmentRepository
+}
+type untouchableProjects struct{ repositories.ProjectRepository }
+type untouchableSeries struct{ repositories.SeriesRepository }
+type untouchableUsers struct{ repositories.UserRepository }
+type untouchableShares struct{ repositories.ShareRepository }
+type untouchableBlobs struct{ storage.BlobStore }
+
+// withoutStorage runs f with every repository and the blob store replaced
+// by untouchable ones.
+func withoutStorage(f func()) {
+	todos, tags, subtasks := initializers.TodoRepository, initializers.TagRepository, initializers.SubtaskRepository
+	comments, attachments := initializers.CommentRepository, initializers.AttachmentRepository
+	projects, series, users := initializers.ProjectRepository, initializers.SeriesRepository, initializers.UserRepository
+	shares, blobs := initializers.ShareRepository, initializers.BlobStore
+	defer func() {
+		initializers.TodoRepository, initializers.TagRepository, initializers.SubtaskRepository = todos, tags, subtasks
+		initializers.CommentRepository, initializers.AttachmentRepository = comments, attachments
+		initializers.ProjectRepository, initializers.SeriesRepository, initializers.UserRepository = projects, series, users
+		initializers.ShareRepository, initializers.BlobStore = shares, blobs
+	}()
+
+	initializers.TodoRepository, initializers.TagRepository, initializers.SubtaskRepository = untouchableTodos{}, untouchableTags{}, untouchableSubtasks{}
+	initializers.CommentRepository, initializers.AttachmentRepository = untouchableComments{}, untouchableAttachments{}
+	initializers.ProjectRepository, initializers.SeriesRepository, initializers.UserRepository = untouchableProjects{}, untouchableSeries{}, untouchableUsers{}
+	initializers.ShareRepository, initializers.BlobStore = untouchableShares{}, untouchableBlobs{}
+
+	f()
+}
+
+func sendAs(r *gin.Engine, token, method, target, payload string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, target, bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	controllers.Routes(r)
+
+	auth := services.NewAuthService(initializers.UserRepository, initializers.Auth)
+
+	// The first user is the admin, later ones are editors
+	assert.Equal(t, models.RoleAdmin, testUser.Role)
+	viewer, editorToken, err := logIn("viewer")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	assert.Equal(t, models.RoleEditor, viewer.Role)
+
+	// Only admins manage roles
+	assert.Equal(t, http.StatusForbidden, sendAs(r, editorToken, "GET", "/admin/users", "").Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, editorToken, "PUT", fmt.Sprintf("/admin/users/%d/role", viewer.ID), `{"role": "admin"}`).Code)
+
+	w := sendAs(r, testToken, "GET", "/admin/users", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var listed map[string][]models.User
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	if assert.NotEmpty(t, listed["users"]) {
+		assert.Equal(t, testUser.ID, listed["users"][0].ID)
+		last := listed["users"][len(listed["users"])-1]
+		assert.Equal(t, viewer.ID, last.ID)
+		assert.Equal(t, models.RoleEditor, last.Role)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, sendAs(r, testToken, "PUT", fmt.Sprintf("/admin/users/%d/role", viewer.ID), `{"role": "owner"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(r, testToken, "PUT", fmt.Sprintf("/admin/users/%d/role", viewer.ID), `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, testToken, "PUT", "/admin/users/999999/role", `{"role": "viewer"}`).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(r, testToken, "PUT", fmt.Sprintf("/admin/users/%d/role", testUser.ID), `{"role": "editor"}`).Code)
+
+	w = sendAs(r, testToken, "PUT", fmt.Sprintf("/admin/users/%d/role", viewer.ID), `{"role": "viewer"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var assigned map[string]models.User
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &assigned))
+	assert.Equal(t, models.RoleViewer, assigned["user"].Role)
+
+	// The new role comes with the next token
+	tokens, err := auth.Login("viewer", "password")
+	if err != nil {
+		t.Fatalf("Failed to log in: %s", err)
+	}
+	viewerToken := tokens.AccessToken
+	assert.Equal(t, models.RoleViewer, tokens.User.Role)
+
+	// Viewers can read
+	assert.Equal(t, http.StatusOK, sendAs(r, viewerToken, "GET", "/todo", "").Code)
+	assert.Equal(t, http.StatusOK, sendAs(r, viewerToken, "GET", "/tags", "").Code)
+	assert.Equal(t, http.StatusOK, sendAs(r, viewerToken, "GET", "/projects", "").Code)
+
+	// Viewers can't change anything, and are turned away before storage is
+	// touched
+	forbidden := []struct{ method, target, payload string }{
+		{"POST", "/todo", `{"title": "Nope", "body": "Nope"}`},
+		{"PUT", "/todo/1", `{"title": "Nope", "body": "Nope"}`},
+		{"PATCH", "/todo/1", `{"title": "Nope"}`},
+		{"DELETE", "/todo/1", ""},
+		{"POST", "/todo/1/restore", ""},
+		{"POST", "/todo/1/move", `{"after": 2}`},
+		{"PUT", "/todo/1/tags/1", ""},
+		{"DELETE", "/todo/1/tags/1", ""},
+		{"POST", "/todo/1/subtasks", `{"title": "Nope"}`},
+		{"PUT", "/todo/1/subtasks/1", `{"title": "Nope"}`},
+		{"PATCH", "/todo/1/subtasks/1", `{"done": true}`},
+		{"DELETE", "/todo/1/subtasks/1", ""},
+		{"POST", "/todo/1/comments", `{"body": "Nope"}`},
+		{"PUT", "/todo/1/comments/1", `{"body": "Nope"}`},
+		{"DELETE", "/todo/1/comments/1", ""},
+		{"POST", "/todo/1/attachments", ""},
+		{"DELETE", "/todo/1/attachments/1", ""},
+		{"POST", "/todo/1/shares", `{"user": "tester", "role": "viewer"}`},
+		{"DELETE", "/todo/1/shares/1", ""},
+		{"POST", "/tags", `{"name": "nope"}`},
+		{"PUT", "/tags/1", `{"name": "nope"}`},
+		{"DELETE", "/tags/1", ""},
+		{"POST", "/projects", `{"name": "Nope"}`},
+		{"PUT", "/projects/1", `{"name": "Nope"}`},
+		{"DELETE", "/projects/1", ""},
+		{"POST", "/projects/1/shares", `{"user": "tester", "role": "viewer"}`},
+		{"DELETE", "/projects/1/shares/1", ""},
+		{"GET", "/admin/users", ""},
+		{"PUT", fmt.Sprintf("/admin/users/%d/role", viewer.ID), `{"role": "admin"}`},
+	}
+	withoutStorage(func() {
+		for _, request := range forbidden {
+			w := sendAs(r, viewerToken, request.method, request.target, request.payload)
+			assert.Equal(t, http.StatusForbidden, w.Code, request.method+" "+request.target)
+			assert.Contains(t, w.Body.String(), "permission", request.method+" "+request.target)
+		}
+		for _, request := range forbidden[len(forbidden)-2:] {
+			assert.Equal(t, http.StatusForbidden, sendAs(r, editorToken, request.method, request.target, request.payload).Code, request.method+" "+request.target)
+		}
+
+		// Which proves the guard: letting the request through would hit
+		// storage
+		assert.Equal(t, http.StatusInternalServerError, sendAs(r, viewerToken, "GET", "/todo/1", "").Code)
+	})
+
+	// An API key can't do more than its user's role allows
+	key, secret, err := auth.CreateAPIKey(viewer, "sync", []string{"read", "write"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create API key: %s", err)
+	}
+	assert.Equal(t, http.StatusOK, sendAs(r, secret, "GET", "/todo", "").Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, secret, "POST", "/todo", `{"title": "Nope", "body": "Nope"}`).Code)
+	initializers.UserRepository.DeleteAPIKey(viewer.ID, key.ID)
+
+	// Tokens without a role are from before roles and have to be refreshed
+	roleless, err := initializers.Auth.Signer.Sign(jwt.Claims{
+		Subject:   strconv.FormatUint(uint64(viewer.ID), 10),
+		Username:  viewer.Username,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, sendAs(r, roleless, "GET", "/todo", "").Code)
+
+	// Promoting another admin lets the first one step down
+	assert.Equal(t, http.StatusOK, sendAs(r, testToken, "PUT", fmt.Sprintf("/admin/users/%d/role", viewer.ID), `{"role": "admin"}`).Code)
+	assert.Equal(t, http.StatusOK, sendAs(r, testToken, "PUT", fmt.Sprintf("/admin/users/%d/role", testUser.ID), `{"role": "editor"}`).Code)
+	assert.NoError(t, initializers.UserRepository.SetRole(testUser.ID, models.RoleAdmin))
+	assert.NoError(t, initializers.UserRepository.SetRole(viewer.ID, models.RoleViewer))
+
+	// Two admins stepping down at once leave one of them an admin
+	assert.NoError(t, initializers.UserRepository.SetRole(viewer.ID, models.RoleAdmin))
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, id := range []uint{testUser.ID, viewer.ID} {
+		wg.Add(1)
+		go func(i int, id uint) {
+			defer wg.Done()
+			errs[i] = initializers.UserRepository.SetRole(id, models.RoleEditor)
+		}(i, id)
+	}
+	wg.Wait()
+	assert.ElementsMatch(t, []error{nil, repositories.ErrLastAdmin}, errs)
+	assert.NoError(t, initializers.UserRepository.SetRole(testUser.ID, models.RoleAdmin))
+	assert.NoError(t, initializers.UserRepository.SetRole(viewer.ID, models.RoleViewer))
+}
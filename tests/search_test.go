@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTodos(t *testing.T) {
+	todoService := services.NewTodoService(repositories.NewMemoryTodoRepository())
+
+	bodyOnly, _ := todoService.CreateTodo("Groceries", "Buy milk and <b>report</b> paper", false)
+	titleOnly, _ := todoService.CreateTodo("Quarterly Report", "Numbers and charts", false)
+	both, _ := todoService.CreateTodo("Report draft", "Finish the report", false)
+	todoService.CreateTodo("Unrelated", "Nothing to see", false)
+
+	page, err := todoService.SearchTodos("REPORT", services.PageRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Hits, 3)
+	assert.Equal(t, both.ID, page.Hits[0].Todo.ID)
+	assert.Equal(t, titleOnly.ID, page.Hits[1].Todo.ID)
+	assert.Equal(t, bodyOnly.ID, page.Hits[2].Todo.ID)
+	assert.Greater(t, page.Hits[0].Rank, page.Hits[1].Rank)
+	assert.Greater(t, page.Hits[1].Rank, page.Hits[2].Rank)
+
+	assert.Equal(t, "Finish the <mark>report</mark>", page.Hits[0].Snippet)
+	assert.Equal(t, "Buy milk and &lt;b&gt;<mark>report</mark>&lt;/b&gt; paper", page.Hits[2].Snippet)
+
+	page, err = todoService.SearchTodos("report", services.PageRequest{Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Hits, 1)
+	assert.Equal(t, titleOnly.ID, page.Hits[0].Todo.ID)
+
+	_, err = todoService.SearchTodos("  ", services.PageRequest{})
+	assert.ErrorIs(t, err, services.ErrInvalidSearch)
+
+	_, err = todoService.SearchTodos(strings.Repeat("a", services.MaxSearchLength+1), services.PageRequest{})
+	assert.ErrorIs(t, err, services.ErrInvalidSearch)
+}
+
+func TestToDoSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos/search", func(c *gin.Context) {
+		controllers.ToDoSearch(c)
+	})
+
+	req, _ := http.NewRequest("GET", "/todos/search?q=Body+3", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Results []struct {
+			ToDo    struct{ Body string } `json:"todo"`
+			Rank    float64               `json:"rank"`
+			Snippet string                `json:"snippet"`
+		} `json:"results"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Results)
+	for _, result := range response.Results {
+		assert.Contains(t, result.ToDo.Body, "Body 3")
+		assert.Contains(t, result.Snippet, "<mark>")
+		assert.Greater(t, result.Rank, 0.0)
+	}
+
+	reqEmpty, _ := http.NewRequest("GET", "/todos/search", nil)
+	wEmpty := httptest.NewRecorder()
+	r.ServeHTTP(wEmpty, reqEmpty)
+
+	assert.Equal(t, http.StatusBadRequest, wEmpty.Code)
+}
+
+func TestToDoSearchFoldsAnyLetter(t *testing.T) {
+	todo := models.ToDo{Title: "Квартальный Отчет", Body: "Цифры и ГРАФИКИ"}
+	if err := ownTodos().Create(&todo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos/search", func(c *gin.Context) {
+		controllers.ToDoSearch(c)
+	})
+
+	type results struct {
+		Results []struct {
+			ToDo models.ToDo `json:"todo"`
+		} `json:"results"`
+	}
+	for _, q := range []string{"отчет", "ОТЧЕТ", "графики"} {
+		w := sendJSON(r, "GET", "/todos/search?q="+url.QueryEscape(q), "", "")
+		assert.Equal(t, http.StatusOK, w.Code, q)
+
+		var ids []uint
+		for _, result := range decode[results](t, w).Results {
+			ids = append(ids, result.ToDo.ID)
+		}
+		assert.Contains(t, ids, todo.ID, q)
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 func TestGetAllTodos(t *testing.T) {
-	todoService := services.NewTodoService()
+	todoService := services.NewTodoService(initializers.TodoRepository)
 
 	testTodos := []models.ToDo{
 		{Title: "Test ToDo 1", Body: "Test Body 1", Status: true},
@@ -24,9 +24,11 @@ func TestGetAllTodos(t *testing.T) {
 		{Title: "Test ToDo 8", Body: "Test Body 8", Status: false},
 	}
 
-	todos, err := todoService.GetAllTodos()
+	page, err := todoService.GetAllTodos(services.PageRequest{})
 	assert.NoError(t, err)
-	assert.NotNil(t, todos)
+	assert.NotNil(t, page)
+	assert.Equal(t, int64(len(testTodos)), page.Total)
+	todos := page.Todos
 	assert.Len(t, todos, len(testTodos))
 	for _, expectedTodo := range testTodos {
 		found := false
@@ -43,7 +45,7 @@ func TestGetAllTodos(t *testing.T) {
 }
 
 func TestCreateTodo(t *testing.T) {
-	todoService := services.NewTodoService()
+	todoService := services.NewTodoService(initializers.TodoRepository)
 	title := "Test Todo"
 	body := "This is a test todo"
 	status := false
@@ -60,7 +62,7 @@ func TestCreateTodo(t *testing.T) {
 }
 
 func TestFindTodo(t *testing.T) {
-	todoService := services.NewTodoService()
+	todoService := services.NewTodoService(initializers.TodoRepository)
 
 	testTodo := models.ToDo{Title: "Test ToDo 1", Body: "Test Body 1", Status: true}
 	foundTodo, err := todoService.FindTodo("1")
@@ -78,21 +80,21 @@ func TestFindTodo(t *testing.T) {
 }
 
 func TestFindByStatus(t *testing.T) {
-	todoService := services.NewTodoService()
+	todoService := services.NewTodoService(initializers.TodoRepository)
 
-	todos_true, err := todoService.FindByStatus(true)
+	todos_true, err := todoService.FindByStatus(true, services.PageRequest{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, todos_true)
-	for _, line := range todos_true {
+	for _, line := range todos_true.Todos {
 		assert.True(t, line.Status)
 	}
 
-	todos_false, err := todoService.FindByStatus(false)
+	todos_false, err := todoService.FindByStatus(false, services.PageRequest{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, todos_true)
-	for _, line := range todos_false {
+	for _, line := range todos_false.Todos {
 		assert.False(t, line.Status)
 	}
 
@@ -100,10 +102,10 @@ func TestFindByStatus(t *testing.T) {
 }
 
 func TestUpdateTodo(t *testing.T) {
-	todoService := services.NewTodoService()
+	todoService := services.NewTodoService(initializers.TodoRepository)
 
 	testTodo := models.ToDo{Title: "Original Title", Body: "Original Body", Status: false}
-	if err := initializers.DB.Create(&testTodo).Error; err != nil {
+	if err := ownTodos().Create(&testTodo); err != nil {
 		t.Fatalf("Failed to create test todo: %v", err)
 	}
 
@@ -113,8 +115,8 @@ func TestUpdateTodo(t *testing.T) {
 	err := todoService.UpdateTodo(&testTodo, updatedTitle, updatedBody, updatedStatus)
 	assert.NoError(t, err)
 
-	var updatedTodo models.ToDo
-	initializers.DB.First(&updatedTodo, testTodo.ID)
+	updatedTodo, err := initializers.TodoRepository.FindByID(testTodo.ID)
+	assert.NoError(t, err)
 	assert.Equal(t, updatedTitle, updatedTodo.Title)
 	assert.Equal(t, updatedBody, updatedTodo.Body)
 	assert.Equal(t, updatedStatus, updatedTodo.Status)
@@ -123,19 +125,18 @@ func TestUpdateTodo(t *testing.T) {
 }
 
 func TestDeleteTodo(t *testing.T) {
-	todoService := services.NewTodoService()
+	todoService := services.NewTodoService(initializers.TodoRepository)
 
 	testTodo := models.ToDo{Title: "Test Todo", Body: "Test Body", Status: false}
-	if err := initializers.DB.Create(&testTodo).Error; err != nil {
+	if err := ownTodos().Create(&testTodo); err != nil {
 		t.Fatalf("Failed to create test todo: %v", err)
 	}
 
 	err := todoService.DeleteTodo(fmt.Sprintf("%v", testTodo.ID))
 	assert.NoError(t, err)
 
-	var deletedTodo models.ToDo
-	result := initializers.DB.First(&deletedTodo, testTodo.ID)
-	assert.Error(t, result.Error)
+	_, err = initializers.TodoRepository.FindByID(testTodo.ID)
+	assert.Error(t, err)
 
 	err = todoService.DeleteTodo("99999")
 	assert.Error(t, err)
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// listedIDs returns the ids of the todos GET target shows to the user with
+// token.
+func listedIDs(t *testing.T, r *gin.Engine, token string, target string) []uint {
+	w := sendAs(r, token, "GET", target, "")
+	assert.Equal(t, http.StatusOK, w.Code, target)
+
+	var response struct {
+		Todos []models.ToDo `json:"todos"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	ids := []uint{}
+	for _, todo := range response.Todos {
+		ids = append(ids, todo.ID)
+	}
+
+	return ids
+}
+
+func TestShares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	controllers.Routes(r)
+
+	_, friendToken, err := logIn("friend")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	stranger, strangerToken, err := logIn("stranger")
+	if err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	project := models.Project{Name: "Shared project", OwnerID: testUser.ID}
+	if err := initializers.ProjectRepository.Create(&project); err != nil {
+		t.Fatalf("Failed to create project: %s", err)
+	}
+	shared := models.ToDo{Title: "Shared todo", Body: "Body", Status: true}
+	private := models.ToDo{Title: "Private todo", Body: "Body", Status: true}
+	planned := models.ToDo{Title: "Planned todo", Body: "Body", ProjectID: &project.ID}
+	for _, todo := range []*models.ToDo{&shared, &private, &planned} {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to create todo: %s", err)
+		}
+	}
+	sharedURL := fmt.Sprintf("/todo/%d", shared.ID)
+
+	// Todos of others are invisible until shared
+	assert.Equal(t, http.StatusNotFound, sendAs(r, friendToken, "GET", sharedURL, "").Code)
+	assert.NotContains(t, listedIDs(t, r, friendToken, "/todo"), shared.ID)
+
+	// Validation
+	for _, payload := range []string{
+		`{"user": "friend"}`,
+		`{"user": "friend", "role": "admin"}`,
+		`{"user": "nobody", "role": "viewer"}`,
+		`{"user": "tester", "role": "viewer"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, sendAs(r, testToken, "POST", sharedURL+"/shares", payload).Code, payload)
+	}
+
+	// Sharing by email for viewing
+	w := sendAs(r, testToken, "POST", sharedURL+"/shares", `{"user": "Friend@Example.com", "role": "viewer"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	viewing := decode[map[string]models.Share](t, w)["share"]
+	assert.Equal(t, models.ShareViewer, viewing.Role)
+	if assert.NotNil(t, viewing.User) {
+		assert.Equal(t, "friend", viewing.User.Username)
+	}
+	assert.Equal(t, http.StatusConflict, sendAs(r, testToken, "POST", sharedURL+"/shares", `{"user": "friend", "role": "editor"}`).Code)
+
+	// FindTodo, GetAllTodos and FindByStatus include shared todos
+	assert.Equal(t, http.StatusOK, sendAs(r, friendToken, "GET", sharedURL, "").Code)
+	listed := listedIDs(t, r, friendToken, "/todo")
+	assert.Contains(t, listed, shared.ID)
+	assert.NotContains(t, listed, private.ID)
+	assert.Contains(t, listedIDs(t, r, friendToken, "/todo?status=true"), shared.ID)
+	assert.NotContains(t, listedIDs(t, r, friendToken, "/todo?status=false"), shared.ID)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, strangerToken, "GET", sharedURL, "").Code)
+
+	w = sendAs(r, friendToken, "GET", sharedURL+"/shares", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Username":"friend"`)
+
+	// Viewers can't change anything
+	for _, request := range []struct{ method, target, payload string }{
+		{"PUT", sharedURL, `{"title": "Changed", "body": "Changed", "status": false}`},
+		{"PATCH", sharedURL, `{"title": "Changed"}`},
+		{"DELETE", sharedURL, ""},
+		{"POST", sharedURL + "/subtasks", `{"title": "Step"}`},
+		{"POST", sharedURL + "/comments", `{"body": "Hi"}`},
+		{"POST", sharedURL + "/shares", `{"user": "stranger", "role": "viewer"}`},
+	} {
+		assert.Equal(t, http.StatusForbidden, sendAs(r, friendToken, request.method, request.target, request.payload).Code, request.method+" "+request.target)
+	}
+
+	// Only owners revoke the shares of others
+	shareURL := fmt.Sprintf("%s/shares/%d", sharedURL, viewing.ID)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, strangerToken, "DELETE", shareURL, "").Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, testToken, "DELETE", shareURL, "").Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, testToken, "DELETE", shareURL, "").Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, friendToken, "GET", sharedURL, "").Code)
+
+	// Editors can change the todo, but not delete it
+	w = sendAs(r, testToken, "POST", sharedURL+"/shares", `{"user": "friend", "role": "editor"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	editing := decode[map[string]models.Share](t, w)["share"]
+	assert.Equal(t, http.StatusOK, sendAs(r, friendToken, "PATCH", sharedURL, `{"title": "Changed by friend"}`).Code)
+	assert.Equal(t, http.StatusCreated, sendAs(r, friendToken, "POST", sharedURL+"/subtasks", `{"title": "Step"}`).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, friendToken, "DELETE", sharedURL, "").Code)
+
+	// Comments are changed by their author or an owner of the todo
+	w = sendAs(r, friendToken, "POST", sharedURL+"/comments", `{"body": "From friend"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	friendComment := fmt.Sprintf("%s/comments/%d", sharedURL, decode[map[string]models.Comment](t, w)["comment"].ID)
+	w = sendAs(r, testToken, "POST", sharedURL+"/comments", `{"body": "From owner"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	ownerComment := fmt.Sprintf("%s/comments/%d", sharedURL, decode[map[string]models.Comment](t, w)["comment"].ID)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, friendToken, "PUT", ownerComment, `{"body": "Changed"}`).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, friendToken, "DELETE", ownerComment, "").Code)
+	assert.Equal(t, http.StatusOK, sendAs(r, friendToken, "PUT", friendComment, `{"body": "Changed"}`).Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, testToken, "DELETE", friendComment, "").Code)
+
+	found, err := ownTodos().FindByID(shared.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Changed by friend", found.Title)
+	assert.Equal(t, testUser.ID, found.OwnerID)
+
+	// Anybody can give up a share of their own
+	assert.Equal(t, http.StatusNoContent, sendAs(r, friendToken, "DELETE", fmt.Sprintf("%s/shares/%d", sharedURL, editing.ID), "").Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, friendToken, "GET", sharedURL, "").Code)
+
+	// Sharing a project shares the owner's todos in it, including later ones
+	projectURL := fmt.Sprintf("/projects/%d", project.ID)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, testToken, "POST", "/projects/999999/shares", `{"user": "friend", "role": "owner"}`).Code)
+	w = sendAs(r, testToken, "POST", projectURL+"/shares", `{"user": "friend", "role": "owner"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	projectShare := decode[map[string]models.Share](t, w)["share"]
+
+	later := models.ToDo{Title: "Later todo", Body: "Body", ProjectID: &project.ID}
+	if err := ownTodos().Create(&later); err != nil {
+		t.Fatalf("Failed to create todo: %s", err)
+	}
+	listed = listedIDs(t, r, friendToken, "/todo")
+	assert.Contains(t, listed, planned.ID)
+	assert.Contains(t, listed, later.ID)
+	assert.NotContains(t, listed, shared.ID)
+	assert.ElementsMatch(t, []uint{planned.ID, later.ID}, listedIDs(t, r, friendToken, projectURL+"/todos"))
+
+	// Owners through a share change the comments of others too
+	plannedURL := fmt.Sprintf("/todo/%d", planned.ID)
+	w = sendAs(r, testToken, "POST", plannedURL+"/comments", `{"body": "From owner"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	ownerComment = fmt.Sprintf("%s/comments/%d", plannedURL, decode[map[string]models.Comment](t, w)["comment"].ID)
+	assert.Equal(t, http.StatusOK, sendAs(r, friendToken, "PUT", ownerComment, `{"body": "Changed by friend"}`).Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, friendToken, "DELETE", ownerComment, "").Code)
+
+	var shares map[string][]models.Share
+	w = sendAs(r, testToken, "GET", projectURL+"/shares", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &shares))
+	assert.Len(t, shares["shares"], 1)
+	w = sendAs(r, friendToken, "GET", projectURL+"/shares", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &shares))
+	assert.Len(t, shares["shares"], 1)
+
+	// Projects of others are invisible until shared, and only their owner
+	// deletes them. Nobody else can file todos into them either.
+	strangersOwn := models.ToDo{Title: "Stranger's own todo", Body: "Body", OwnerID: stranger.ID}
+	if err := initializers.TodoRepository.Create(&strangersOwn); err != nil {
+		t.Fatalf("Failed to create todo: %s", err)
+	}
+	var projects map[string][]models.Project
+	w = sendAs(r, friendToken, "GET", "/projects", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &projects))
+	assert.Len(t, projects["projects"], 1)
+	w = sendAs(r, strangerToken, "GET", "/projects", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &projects))
+	assert.Empty(t, projects["projects"])
+	for _, request := range []struct{ method, target, payload string }{
+		{"GET", projectURL, ""},
+		{"GET", projectURL + "/todos", ""},
+		{"GET", projectURL + "/shares", ""},
+		{"PUT", projectURL, `{"name": "Stolen"}`},
+		{"DELETE", projectURL, ""},
+		{"POST", projectURL + "/shares", `{"user": "stranger", "role": "owner"}`},
+		{"POST", "/todo", fmt.Sprintf(`{"title": "Filed", "body": "", "project_id": %d}`, project.ID)},
+		{"PATCH", fmt.Sprintf("/todo/%d", strangersOwn.ID), fmt.Sprintf(`{"project_id": %d}`, project.ID)},
+	} {
+		assert.Equal(t, http.StatusNotFound, sendAs(r, strangerToken, request.method, request.target, request.payload).Code, request.method+" "+request.target)
+	}
+	assert.Equal(t, http.StatusOK, sendAs(r, friendToken, "PUT", projectURL, `{"name": "Renamed by friend"}`).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(r, friendToken, "DELETE", projectURL, "").Code)
+
+	// Owners can delete and restore, and the todo stays in its owner's trash
+	laterURL := fmt.Sprintf("/todo/%d", later.ID)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, friendToken, "DELETE", laterURL, "").Code)
+	assert.Contains(t, listedIDs(t, r, testToken, "/todo/trash"), later.ID)
+	assert.NotContains(t, listedIDs(t, r, friendToken, "/todo/trash"), later.ID)
+	assert.Equal(t, http.StatusOK, sendAs(r, friendToken, "POST", laterURL+"/restore", "").Code)
+
+	// Owners can share further
+	w = sendAs(r, friendToken, "POST", laterURL+"/shares", `{"user": "stranger", "role": "viewer"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, testUser.ID, decode[map[string]models.Share](t, w)["share"].OwnerID)
+	assert.Equal(t, http.StatusOK, sendAs(r, strangerToken, "GET", laterURL, "").Code)
+
+	// Todos an editor puts into a project are seen by its owner and the
+	// other members, and still belong to the editor
+	w = sendAs(r, testToken, "POST", projectURL+"/shares", `{"user": "stranger", "role": "editor"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	projectEditing := decode[map[string]models.Share](t, w)["share"]
+	w = sendAs(r, strangerToken, "POST", "/todo", fmt.Sprintf(`{"title": "Stranger's todo", "body": "Body", "project_id": %d}`, project.ID))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	strangers := decode[map[string]models.ToDo](t, w)["todo"]
+	assert.Equal(t, stranger.ID, strangers.OwnerID)
+	w = sendAs(r, testToken, "GET", projectURL+"/todos", "")
+	assert.Equal(t, int64(3), decode[pageResponse](t, w).Total)
+	assert.Contains(t, todoIDs(decode[pageResponse](t, w).Todos), strangers.ID)
+	assert.Contains(t, listedIDs(t, r, friendToken, "/todo"), strangers.ID)
+	assert.Equal(t, http.StatusOK, sendAs(r, testToken, "PATCH", fmt.Sprintf("/todo/%d", strangers.ID), `{"title": "Checked by the owner"}`).Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, testToken, "DELETE", fmt.Sprintf("%s/shares/%d", projectURL, projectEditing.ID), "").Code)
+	assert.NotContains(t, listedIDs(t, r, strangerToken, "/todo"), planned.ID)
+
+	// Leaving a project share
+	assert.Equal(t, http.StatusNotFound, sendAs(r, strangerToken, "DELETE", fmt.Sprintf("%s/shares/%d", projectURL, projectShare.ID), "").Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, friendToken, "DELETE", fmt.Sprintf("%s/shares/%d", projectURL, projectShare.ID), "").Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, friendToken, "GET", fmt.Sprintf("/todo/%d", planned.ID), "").Code)
+
+	// Deleting a project trashes only its owner's todos and drops its shares
+	assert.Equal(t, http.StatusCreated, sendAs(r, testToken, "POST", projectURL+"/shares", `{"user": "friend", "role": "viewer"}`).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(r, friendToken, "POST", "/todo", fmt.Sprintf(`{"title": "Filed", "body": "", "project_id": %d}`, project.ID)).Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, testToken, "DELETE", projectURL+"?cascade=true", "").Code)
+	assert.Contains(t, listedIDs(t, r, testToken, "/todo/trash"), planned.ID)
+	kept, err := initializers.TodoRepository.FindByID(strangers.ID)
+	if assert.NoError(t, err) {
+		assert.Nil(t, kept.ProjectID)
+	}
+	left, err := initializers.ShareRepository.ListForProject(testUser.ID, project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, left)
+
+	// Purging a todo drops its shares
+	privateURL := fmt.Sprintf("/todo/%d", private.ID)
+	assert.Equal(t, http.StatusCreated, sendAs(r, testToken, "POST", privateURL+"/shares", `{"user": "friend", "role": "viewer"}`).Code)
+	assert.Equal(t, http.StatusNoContent, sendAs(r, testToken, "DELETE", privateURL+"?hard=true", "").Code)
+	left, err = initializers.ShareRepository.ListForTodo(private.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, left)
+}
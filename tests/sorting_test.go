@@ -0,0 +1,149 @@
+package main
+
+import (
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	fields, err := services.ParseSort("-created_at, title:asc,status:desc,+id")
+	assert.NoError(t, err)
+	assert.Equal(t, []repositories.SortField{
+		{Field: "created_at", Desc: true},
+		{Field: "title"},
+		{Field: "status", Desc: true},
+		{Field: "id"},
+	}, fields)
+
+	for _, spec := range []string{"password", "title;DROP TABLE to_dos", "title:up", "title,-title", ","} {
+		_, err := services.ParseSort(spec)
+		assert.ErrorIs(t, err, services.ErrInvalidSort, spec)
+	}
+}
+
+func TestToDoIndexSorting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	w, all := getPage(t, r, "/todos?limit=100&sort=-title")
+	assert.Equal(t, http.StatusOK, w.Code)
+	for i := 1; i < len(all.Todos); i++ {
+		assert.GreaterOrEqual(t, all.Todos[i-1].Title, all.Todos[i].Title)
+	}
+
+	// Paging with cursors must visit rows in exactly the same order.
+	w, all = getPage(t, r, "/todos?limit=100&sort=status:desc,-created_at")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var seen []uint
+	target := "/todos?limit=3&sort=status:desc,-created_at"
+	for target != "" {
+		w, response := getPage(t, r, target)
+		assert.Equal(t, http.StatusOK, w.Code)
+		for _, todo := range response.Todos {
+			seen = append(seen, todo.ID)
+		}
+
+		target = ""
+		if response.NextCursor != nil {
+			target = "/todos?limit=3&sort=status:desc,-created_at&cursor=" + url.QueryEscape(*response.NextCursor)
+
+			// A cursor only makes sense for the sort it was issued for.
+			w, _ := getPage(t, r, "/todos?limit=3&sort=title&cursor="+url.QueryEscape(*response.NextCursor))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	}
+
+	var expected []uint
+	for _, todo := range all.Todos {
+		expected = append(expected, todo.ID)
+	}
+	assert.Equal(t, expected, seen)
+
+	w, _ = getPage(t, r, "/todos?sort=title%3BDROP%20TABLE%20to_dos")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestToDoIndexSortByDate(t *testing.T) {
+	now := time.Now().UTC()
+	at := func(t time.Time) *time.Time { return &t }
+
+	past := models.ToDo{Title: "Sorted By Date Past", DueAt: at(now.Add(-time.Hour))}
+	undated := models.ToDo{Title: "Sorted By Date Never"}
+	later := models.ToDo{Title: "Sorted By Date Later", DueAt: at(now.Add(48 * time.Hour))}
+	undatedToo := models.ToDo{Title: "Sorted By Date Never Too"}
+	soon := models.ToDo{Title: "Sorted By Date Soon", DueAt: at(now.Add(time.Hour))}
+
+	todos := []*models.ToDo{&past, &undated, &later, &undatedToo, &soon}
+	for _, todo := range todos {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	defer func() {
+		for _, todo := range todos {
+			initializers.TodoRepository.Purge(todo.ID, 0)
+		}
+	}()
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+
+	filter := url.QueryEscape(`title contains "Sorted By Date"`)
+
+	// Todos without a due date come last in either direction
+	for sort, dated := range map[string][]uint{
+		"due_at":  {past.ID, soon.ID, later.ID},
+		"-due_at": {later.ID, soon.ID, past.ID},
+	} {
+		w, page := getPage(t, r, "/todos?sort="+sort+"&filter="+filter)
+		assert.Equal(t, http.StatusOK, w.Code, sort)
+		all := todoIDs(page.Todos)
+		if !assert.Len(t, all, len(todos), sort) {
+			continue
+		}
+		assert.Equal(t, dated, all[:3], sort)
+		assert.ElementsMatch(t, []uint{undated.ID, undatedToo.ID}, all[3:], sort)
+
+		// Cursors step over the missing dates too
+		var walked []uint
+		target := "/todos?limit=2&sort=" + sort + "&filter=" + filter
+		for {
+			w, page = getPage(t, r, target)
+			assert.Equal(t, http.StatusOK, w.Code, sort)
+			walked = append(walked, todoIDs(page.Todos)...)
+			if page.NextCursor == nil {
+				break
+			}
+			target = "/todos?limit=2&sort=" + sort + "&filter=" + filter + "&cursor=" + url.QueryEscape(*page.NextCursor)
+		}
+		assert.Equal(t, all, walked, sort)
+
+		w, page = getPage(t, r, "/todos?limit=2&sort="+sort+"&filter="+filter+"&cursor="+url.QueryEscape(*page.PrevCursor))
+		assert.Equal(t, http.StatusOK, w.Code, sort)
+		assert.Equal(t, all[2:4], todoIDs(page.Todos), sort)
+	}
+
+	for _, sort := range []string{"start_at", "-completed_at"} {
+		w, _ := getPage(t, r, "/todos?sort="+sort)
+		assert.Equal(t, http.StatusOK, w.Code, sort)
+	}
+}
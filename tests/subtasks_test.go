@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type subtaskList struct {
+	Subtasks   []models.Subtask `json:"subtasks"`
+	Completion *int             `json:"completion"`
+}
+
+func TestSubtasks(t *testing.T) {
+	todo := models.ToDo{Title: "Checklist Title"}
+	other := models.ToDo{Title: "Other Checklist", State: models.StateBlocked}
+	for _, todo := range []*models.ToDo{&todo, &other} {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+	defer initializers.TodoRepository.Purge(other.ID, 0)
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoShow(c)
+	})
+	r.GET("/todos/:id/subtasks", func(c *gin.Context) {
+		controllers.SubtaskIndex(c)
+	})
+	r.POST("/todos/:id/subtasks", func(c *gin.Context) {
+		controllers.SubtaskCreate(c)
+	})
+	r.GET("/todos/:id/subtasks/:subtaskId", func(c *gin.Context) {
+		controllers.SubtaskShow(c)
+	})
+	r.PUT("/todos/:id/subtasks/:subtaskId", func(c *gin.Context) {
+		controllers.SubtaskUpdate(c)
+	})
+	r.PATCH("/todos/:id/subtasks/:subtaskId", func(c *gin.Context) {
+		controllers.SubtaskPatch(c)
+	})
+	r.DELETE("/todos/:id/subtasks/:subtaskId", func(c *gin.Context) {
+		controllers.SubtaskDelete(c)
+	})
+
+	base := fmt.Sprintf("/todos/%d/subtasks", todo.ID)
+	list := func() subtaskList {
+		w := sendJSON(r, "GET", base, "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response subtaskList
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		return response
+	}
+	titles := func(subtasks []models.Subtask) []string {
+		titles := []string{}
+		for i, subtask := range subtasks {
+			assert.Equal(t, i+1, subtask.Position)
+			titles = append(titles, subtask.Title)
+		}
+		return titles
+	}
+	show := func() (models.ToDo, *int) {
+		w := sendJSON(r, "GET", fmt.Sprintf("/todos/%d", todo.ID), "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			ToDo       models.ToDo `json:"todo"`
+			Completion *int        `json:"completion"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		return response.ToDo, response.Completion
+	}
+
+	_, completion := show()
+	assert.Nil(t, completion)
+	assert.Nil(t, list().Completion)
+
+	subtasks := map[string]models.Subtask{}
+	for _, payload := range []string{`{"title": "One"}`, `{"title": "Two"}`, `{"title": "Three"}`, `{"title": "Zero", "position": 1}`} {
+		w := sendJSON(r, "POST", base, "application/json", payload)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		subtask := decode[map[string]models.Subtask](t, w)["subtask"]
+		subtasks[subtask.Title] = subtask
+	}
+	assert.Equal(t, 1, subtasks["Zero"].Position)
+	assert.Equal(t, []string{"Zero", "One", "Two", "Three"}, titles(list().Subtasks))
+	assert.Equal(t, 0, *list().Completion)
+
+	for _, payload := range []string{`{}`, `{"title": " "}`, `{"title": "Four", "position": 0}`} {
+		w := sendJSON(r, "POST", base, "application/json", payload)
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+	}
+
+	target := func(title string) string {
+		return fmt.Sprintf("%s/%d", base, subtasks[title].ID)
+	}
+
+	w := sendJSON(r, "PATCH", target("Three"), "application/json", `{"position": 2}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, decode[map[string]models.Subtask](t, w)["subtask"].Position)
+	assert.Equal(t, []string{"Zero", "Three", "One", "Two"}, titles(list().Subtasks))
+
+	w = sendJSON(r, "PUT", target("Zero"), "application/json", `{"title": "Zero"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "PUT", target("Zero"), "application/json", `{"title": "Start", "done": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, decode[map[string]models.Subtask](t, w)["subtask"].Position)
+
+	w = sendJSON(r, "GET", target("Zero"), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Start", decode[map[string]models.Subtask](t, w)["subtask"].Title)
+
+	_, completion = show()
+	assert.Equal(t, 25, *completion)
+
+	// Finishing the last subtask finishes the todo
+	for _, title := range []string{"One", "Two", "Three"} {
+		w = sendJSON(r, "PATCH", target(title), "application/json", `{"done": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	shown, completion := show()
+	assert.Equal(t, 100, *completion)
+	assert.True(t, shown.Status)
+
+	// An open subtask reopens it
+	w = sendJSON(r, "POST", base, "application/json", `{"title": "Four"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	subtasks["Four"] = decode[map[string]models.Subtask](t, w)["subtask"]
+	shown, completion = show()
+	assert.Equal(t, 80, *completion)
+	assert.False(t, shown.Status)
+
+	w = sendJSON(r, "DELETE", target("Three"), "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{"Start", "One", "Two", "Four"}, titles(list().Subtasks))
+
+	w = sendJSON(r, "DELETE", target("Four"), "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	shown, _ = show()
+	assert.True(t, shown.Status)
+
+	// Subtasks only exist under their own todo
+	w = sendJSON(r, "GET", fmt.Sprintf("/todos/%d/subtasks/%d", other.ID, subtasks["One"].ID), "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(r, "DELETE", target("Three"), "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(r, "GET", "/todos/123123/subtasks", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The workflow still decides: a blocked todo can't be done
+	w = sendJSON(r, "POST", fmt.Sprintf("/todos/%d/subtasks", other.ID), "application/json", `{"title": "Only", "done": true}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	blocked, err := ownTodos().FindByID(other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StateBlocked, blocked.State)
+	assert.False(t, blocked.Status)
+}
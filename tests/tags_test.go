@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func tagNames(todo models.ToDo) []string {
+	names := []string{}
+	for _, tag := range todo.Tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+func TestTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/tags", func(c *gin.Context) {
+		controllers.TagCreate(c)
+	})
+	r.GET("/tags", func(c *gin.Context) {
+		controllers.TagIndex(c)
+	})
+	r.GET("/tags/:id", func(c *gin.Context) {
+		controllers.TagShow(c)
+	})
+	r.PUT("/tags/:id", func(c *gin.Context) {
+		controllers.TagUpdate(c)
+	})
+	r.DELETE("/tags/:id", func(c *gin.Context) {
+		controllers.TagDelete(c)
+	})
+
+	w := sendJSON(r, "POST", "/tags", "application/json", `{"name": " release-2.3 "}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	release := decode[map[string]models.Tag](t, w)["tag"]
+	assert.Equal(t, "release-2.3", release.Name)
+
+	w = sendJSON(r, "POST", "/tags", "application/json", `{"name": "backend"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	backend := decode[map[string]models.Tag](t, w)["tag"]
+
+	w = sendJSON(r, "POST", "/tags", "application/json", `{"name": "backend"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = sendJSON(r, "POST", "/tags", "application/json", `{"name": "front,back"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "GET", "/tags", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list map[string][]models.Tag
+	err := json.Unmarshal(w.Body.Bytes(), &list)
+	assert.NoError(t, err)
+	if assert.Len(t, list["tags"], 2) {
+		assert.Equal(t, "backend", list["tags"][0].Name)
+		assert.Equal(t, "release-2.3", list["tags"][1].Name)
+	}
+
+	w = sendJSON(r, "PUT", fmt.Sprintf("/tags/%d", backend.ID), "application/json", `{"name": "release-2.3"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = sendJSON(r, "PUT", fmt.Sprintf("/tags/%d", backend.ID), "application/json", `{"name": "server"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "server", decode[map[string]models.Tag](t, w)["tag"].Name)
+
+	w = sendJSON(r, "GET", fmt.Sprintf("/tags/%d", backend.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "server", decode[map[string]models.Tag](t, w)["tag"].Name)
+
+	for _, tag := range []models.Tag{backend, release} {
+		w = sendJSON(r, "DELETE", fmt.Sprintf("/tags/%d", tag.ID), "", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	}
+
+	w = sendJSON(r, "GET", fmt.Sprintf("/tags/%d", backend.ID), "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(r, "DELETE", fmt.Sprintf("/tags/%d", backend.ID), "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestToDoTags(t *testing.T) {
+	backend := models.Tag{Name: "backend", OwnerID: testUser.ID}
+	release := models.Tag{Name: "release-2.3", OwnerID: testUser.ID}
+	for _, tag := range []*models.Tag{&backend, &release} {
+		if err := initializers.TagRepository.Create(tag); err != nil {
+			t.Fatalf("Failed to initialize test tag: %s", err)
+		}
+	}
+	both := models.ToDo{Title: "Tagged Both"}
+	one := models.ToDo{Title: "Tagged One"}
+	for _, todo := range []*models.ToDo{&both, &one} {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	defer initializers.TagRepository.Delete(backend.ID)
+	defer initializers.TodoRepository.Purge(one.ID, 0)
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+	r.GET("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoShow(c)
+	})
+	r.DELETE("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoDelete(c)
+	})
+	r.PUT("/todos/:id/tags/:tagId", func(c *gin.Context) {
+		controllers.ToDoAttachTag(c)
+	})
+	r.DELETE("/todos/:id/tags/:tagId", func(c *gin.Context) {
+		controllers.ToDoDetachTag(c)
+	})
+	r.DELETE("/tags/:id", func(c *gin.Context) {
+		controllers.TagDelete(c)
+	})
+
+	attach := func(todo models.ToDo, tag models.Tag) *httptest.ResponseRecorder {
+		return sendJSON(r, "PUT", fmt.Sprintf("/todos/%d/tags/%d", todo.ID, tag.ID), "", "")
+	}
+
+	w := attach(both, release)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = attach(both, backend)
+	assert.Equal(t, http.StatusOK, w.Code)
+	tagged := decode[map[string]models.ToDo](t, w)["todo"]
+	assert.Equal(t, []string{"backend", "release-2.3"}, tagNames(tagged))
+	assert.Equal(t, uint(3), tagged.Version)
+	assert.Equal(t, fmt.Sprintf(`"%d-3"`, both.ID), w.Header().Get("ETag"))
+
+	// Attaching again changes nothing
+	w = attach(both, backend)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint(3), decode[map[string]models.ToDo](t, w)["todo"].Version)
+
+	w = attach(one, backend)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = attach(one, models.Tag{ID: 123123})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	ids := func(query string) []uint {
+		w, page := getPage(t, r, "/todos?limit=100&"+query)
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		return todoIDs(page.Todos)
+	}
+	assert.Subset(t, ids("tags=backend"), []uint{both.ID, one.ID})
+	assert.Subset(t, ids("tags=release-2.3,unknown"), []uint{both.ID})
+	assert.NotContains(t, ids("tags=release-2.3,unknown"), one.ID)
+	assert.Contains(t, ids("tags_all=backend,release-2.3"), both.ID)
+	assert.NotContains(t, ids("tags_all=backend,release-2.3"), one.ID)
+	assert.NotContains(t, ids("tags_all=backend,unknown"), both.ID)
+
+	// Another user's tag of the same name, say on a shared todo, doesn't
+	// stand in for a missing one
+	othersBackend := models.Tag{Name: "backend", OwnerID: testUser.ID + 1000}
+	if err := initializers.TagRepository.Create(&othersBackend); err != nil {
+		t.Fatalf("Failed to initialize test tag: %s", err)
+	}
+	defer initializers.TagRepository.Delete(othersBackend.ID)
+	assert.NoError(t, initializers.TagRepository.Attach(one.ID, othersBackend.ID))
+	assert.NotContains(t, ids("tags_all=backend,release-2.3"), one.ID)
+	assert.NoError(t, initializers.TagRepository.Detach(one.ID, othersBackend.ID))
+
+	w, _ = getPage(t, r, "/todos?tags=backend,")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "DELETE", fmt.Sprintf("/todos/%d/tags/%d", one.ID, backend.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, tagNames(decode[map[string]models.ToDo](t, w)["todo"]))
+	w = sendJSON(r, "DELETE", fmt.Sprintf("/todos/%d/tags/%d", one.ID, backend.ID), "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Deleting a tag detaches it and changes the todo's ETag
+	w = sendJSON(r, "DELETE", fmt.Sprintf("/tags/%d", release.ID), "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = sendJSON(r, "GET", fmt.Sprintf("/todos/%d", both.ID), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"backend"}, tagNames(decode[map[string]models.ToDo](t, w)["todo"]))
+	assert.Equal(t, uint(4), decode[map[string]models.ToDo](t, w)["todo"].Version)
+
+	// A tagged todo can be deleted for good
+	w = sendJSON(r, "DELETE", fmt.Sprintf("/todos/%d?hard=true", both.ID), "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
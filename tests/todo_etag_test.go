@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/services"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendWithHeader(r *gin.Engine, method, target, payload, header, value string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, target, bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestToDoETags(t *testing.T) {
+	testToDo := models.ToDo{Title: "Versioned Title", Body: "Versioned Body", Status: false}
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoShow(c)
+	})
+	r.PUT("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoUpdate(c)
+	})
+	r.PATCH("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoPatch(c)
+	})
+	r.DELETE("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoDelete(c)
+	})
+
+	target := fmt.Sprintf("/todos/%d", testToDo.ID)
+
+	w := sendWithHeader(r, "GET", target, "", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	original := w.Header().Get("ETag")
+	assert.NotEmpty(t, original)
+
+	w = sendWithHeader(r, "GET", target, "", "If-None-Match", original)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = sendWithHeader(r, "GET", target, "", "If-None-Match", `"other", W/`+original)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	payload := `{"title": "Updated Title", "body": "Updated Body", "status": true}`
+	w = sendWithHeader(r, "PUT", target, payload, "If-Match", `"stale"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// If-Match uses strong comparison, so a weak validator never matches.
+	w = sendWithHeader(r, "PUT", target, payload, "If-Match", "W/"+original)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = sendWithHeader(r, "PUT", target, payload, "If-Match", original)
+	assert.Equal(t, http.StatusOK, w.Code)
+	updated := w.Header().Get("ETag")
+	assert.NotEqual(t, original, updated)
+
+	w = sendWithHeader(r, "GET", target, "", "If-None-Match", original)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, updated, w.Header().Get("ETag"))
+
+	// The first client's tag is now stale for every write.
+	w = sendWithHeader(r, "PATCH", target, `{"status": false}`, "If-Match", original)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = sendWithHeader(r, "DELETE", target, "", "If-Match", original)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = sendWithHeader(r, "PATCH", target, `{"status": false}`, "If-Match", updated)
+	assert.Equal(t, http.StatusOK, w.Code)
+	patched := w.Header().Get("ETag")
+
+	w = sendWithHeader(r, "DELETE", target, "", "If-Match", patched)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = sendWithHeader(r, "DELETE", target, "", "If-Match", patched)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestToDoETagsFollowComments(t *testing.T) {
+	testToDo := models.ToDo{Title: "Discussed Versioned Title", Body: "Body"}
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+	defer initializers.TodoRepository.Purge(testToDo.ID, 0)
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoShow(c)
+	})
+	r.POST("/todos/:id/comments", func(c *gin.Context) {
+		controllers.CommentCreate(c)
+	})
+	r.PUT("/todos/:id/comments/:commentId", func(c *gin.Context) {
+		controllers.CommentUpdate(c)
+	})
+	r.DELETE("/todos/:id/comments/:commentId", func(c *gin.Context) {
+		controllers.CommentDelete(c)
+	})
+
+	target := fmt.Sprintf("/todos/%d", testToDo.ID)
+	etag := sendWithHeader(r, "GET", target, "", "", "").Header().Get("ETag")
+
+	// Adding, editing and deleting a comment each make cached copies stale
+	w := sendWithHeader(r, "POST", target+"/comments", `{"body": "First"}`, "", "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	comment := decode[map[string]models.Comment](t, w)["comment"]
+
+	w = sendWithHeader(r, "GET", target, "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), decode[map[string]models.ToDo](t, w)["todo"].CommentCount)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	etag = w.Header().Get("ETag")
+
+	commentURL := fmt.Sprintf("%s/comments/%d", target, comment.ID)
+	assert.Equal(t, http.StatusOK, sendWithHeader(r, "PUT", commentURL, `{"body": "Edited"}`, "", "").Code)
+	w = sendWithHeader(r, "GET", target, "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag = w.Header().Get("ETag")
+
+	assert.Equal(t, http.StatusNoContent, sendWithHeader(r, "DELETE", commentURL, "", "", "").Code)
+	w = sendWithHeader(r, "GET", target, "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(0), decode[map[string]models.ToDo](t, w)["todo"].CommentCount)
+}
+
+func TestUpdateTodoVersionConflict(t *testing.T) {
+	todoService := services.NewTodoService(initializers.TodoRepository)
+
+	created, err := todoService.CreateTodo("Concurrent Title", "Concurrent Body", false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.Version)
+
+	first, err := todoService.FindTodo(fmt.Sprintf("%d", created.ID))
+	assert.NoError(t, err)
+	second, err := todoService.FindTodo(fmt.Sprintf("%d", created.ID))
+	assert.NoError(t, err)
+
+	err = todoService.UpdateTodo(first, "First Writer", "Concurrent Body", false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), first.Version)
+
+	err = todoService.UpdateTodo(second, "Second Writer", "Concurrent Body", false)
+	assert.ErrorIs(t, err, services.ErrVersionConflict)
+	assert.Equal(t, "Concurrent Title", second.Title)
+
+	err = todoService.DeleteTodoVersion(second)
+	assert.ErrorIs(t, err, services.ErrVersionConflict)
+
+	stored, err := todoService.FindTodo(fmt.Sprintf("%d", created.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "First Writer", stored.Title)
+	assert.Equal(t, uint(2), stored.Version)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/ranking"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankingBetween(t *testing.T) {
+	key, err := ranking.Between("i", "j")
+	assert.NoError(t, err)
+	assert.Equal(t, "ii", key)
+
+	first := ranking.After("")
+	keys := []string{first, ranking.After(first)}
+	for i := 0; i < 200; i++ {
+		// Inserting right after the same key again and again is the worst
+		// case for the key length.
+		key, err := ranking.Between(keys[0], keys[1])
+		assert.NoError(t, err)
+		keys = append([]string{keys[0], key}, keys[1:]...)
+	}
+	assert.IsIncreasing(t, keys)
+
+	_, err = ranking.Between("j", "i")
+	assert.ErrorIs(t, err, ranking.ErrInvalidKey)
+	_, err = ranking.Between("i0", "")
+	assert.ErrorIs(t, err, ranking.ErrInvalidKey)
+	assert.Less(t, "i", ranking.After("i"))
+	assert.Less(t, "izzz", ranking.After("izzz"))
+	assert.Less(t, "zzzz", ranking.After("zzzz"))
+}
+
+func TestToDoMove(t *testing.T) {
+	project := models.Project{Name: "Ordered", OwnerID: testUser.ID}
+	if err := initializers.ProjectRepository.Create(&project); err != nil {
+		t.Fatalf("Failed to initialize test project: %s", err)
+	}
+	defer initializers.ProjectRepository.Delete(project.ID, false)
+
+	todos := make([]models.ToDo, 3)
+	for i := range todos {
+		todos[i] = models.ToDo{Title: fmt.Sprintf("Ordered %d", i), ProjectID: &project.ID}
+		if err := ownTodos().Create(&todos[i]); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+		defer initializers.TodoRepository.Purge(todos[i].ID, 0)
+	}
+	a, b, c := todos[0].ID, todos[1].ID, todos[2].ID
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/projects/:id/todos", func(c *gin.Context) {
+		controllers.ProjectTodos(c)
+	})
+	r.POST("/todos/:id/move", func(c *gin.Context) {
+		controllers.ToDoMove(c)
+	})
+
+	order := func() []uint {
+		w := sendJSON(r, "GET", fmt.Sprintf("/projects/%d/todos?sort=rank", project.ID), "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var page struct {
+			Todos []models.ToDo `json:"todos"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return todoIDs(page.Todos)
+	}
+	move := func(id uint, payload string) int {
+		return sendJSON(r, "POST", fmt.Sprintf("/todos/%d/move", id), "application/json", payload).Code
+	}
+
+	// New todos are appended
+	assert.Equal(t, []uint{a, b, c}, order())
+
+	assert.Equal(t, http.StatusOK, move(c, fmt.Sprintf(`{"after": %d}`, a)))
+	assert.Equal(t, []uint{a, c, b}, order())
+
+	assert.Equal(t, http.StatusOK, move(b, fmt.Sprintf(`{"before": %d}`, a)))
+	assert.Equal(t, []uint{b, a, c}, order())
+
+	assert.Equal(t, http.StatusOK, move(c, fmt.Sprintf(`{"after": %d, "before": %d}`, b, a)))
+	assert.Equal(t, []uint{b, c, a}, order())
+
+	// Moving to the end
+	assert.Equal(t, http.StatusOK, move(b, fmt.Sprintf(`{"after": %d}`, a)))
+	assert.Equal(t, []uint{c, a, b}, order())
+
+	// Only the moved todo changes
+	moved, err := initializers.TodoRepository.FindByID(b)
+	assert.NoError(t, err)
+	assert.Equal(t, todos[1].Version+2, moved.Version)
+	untouched, err := initializers.TodoRepository.FindByID(a)
+	assert.NoError(t, err)
+	assert.Equal(t, todos[0].Version, untouched.Version)
+	assert.Equal(t, todos[0].Rank, untouched.Rank)
+
+	assert.Equal(t, http.StatusBadRequest, move(a, `{}`))
+	assert.Equal(t, http.StatusBadRequest, move(a, `{"after": "first"}`))
+	assert.Equal(t, http.StatusBadRequest, move(a, fmt.Sprintf(`{"after": %d}`, a)))
+	assert.Equal(t, http.StatusBadRequest, move(a, `{"before": 99999}`))
+	assert.Equal(t, http.StatusBadRequest, move(a, fmt.Sprintf(`{"after": %d, "before": %d}`, b, c)))
+	assert.Equal(t, http.StatusNotFound, move(99999, fmt.Sprintf(`{"after": %d}`, a)))
+	assert.Equal(t, []uint{c, a, b}, order())
+
+	w := sendWithHeader(r, "POST", fmt.Sprintf("/todos/%d/move", a), fmt.Sprintf(`{"after": %d}`, b), "If-Match", `"stale"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendJSON(r *gin.Engine, method, target, contentType, payload string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, target, bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestToDoPatch(t *testing.T) {
+	testToDo := models.ToDo{Title: "Original Title", Body: "Original Body", Status: false}
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.PATCH("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoPatch(c)
+	})
+
+	target := fmt.Sprintf("/todos/%d", testToDo.ID)
+
+	// Merge patch touches only the supplied member.
+	w := sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		ToDo models.ToDo `json:"todo"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Original Title", response.ToDo.Title)
+	assert.Equal(t, "Original Body", response.ToDo.Body)
+	assert.True(t, response.ToDo.Status)
+
+	// JSON Patch with a passing test operation.
+	w = sendJSON(r, "PATCH", target, "application/json-patch+json",
+		`[{"op": "test", "path": "/status", "value": true}, {"op": "replace", "path": "/title", "value": "Patched Title"}]`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stored, err := initializers.TodoRepository.FindByID(testToDo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Patched Title", stored.Title)
+	assert.Equal(t, "Original Body", stored.Body)
+	assert.True(t, stored.Status)
+
+	// A failing test operation leaves the todo alone.
+	w = sendJSON(r, "PATCH", target, "application/json-patch+json",
+		`[{"op": "test", "path": "/status", "value": false}, {"op": "replace", "path": "/title", "value": "Lost update"}]`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	for _, bad := range []struct {
+		contentType string
+		payload     string
+		code        int
+	}{
+		{"application/merge-patch+json", `{"title": null}`, http.StatusBadRequest},
+		{"application/merge-patch+json", `{"title": "No"}`, http.StatusBadRequest},
+		{"application/merge-patch+json", `{"id": 42}`, http.StatusBadRequest},
+		{"application/merge-patch+json", `{"status": "yes"}`, http.StatusBadRequest},
+		{"application/merge-patch+json", `{"title": `, http.StatusBadRequest},
+		{"application/json-patch+json", `[{"op": "remove", "path": "/body"}]`, http.StatusBadRequest},
+		{"application/json-patch+json", `[{"op": "add", "path": "/owner", "value": 1}]`, http.StatusBadRequest},
+		{"application/json-patch+json", `{"op": "replace"}`, http.StatusBadRequest},
+		{"text/plain", `title=x`, http.StatusUnsupportedMediaType},
+	} {
+		w = sendJSON(r, "PATCH", target, bad.contentType, bad.payload)
+		assert.Equal(t, bad.code, w.Code, bad.payload)
+	}
+
+	stored, err = initializers.TodoRepository.FindByID(testToDo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Patched Title", stored.Title)
+	assert.Equal(t, "Original Body", stored.Body)
+
+	w = sendJSON(r, "PATCH", "/todos/99999", "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestToDoUpdateRequiresFullRepresentation(t *testing.T) {
+	testToDo := models.ToDo{Title: "Original Title", Body: "Original Body", Status: true}
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.PUT("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoUpdate(c)
+	})
+
+	target := fmt.Sprintf("/todos/%d", testToDo.ID)
+
+	for _, payload := range []string{
+		`{"title": "Updated Title", "body": "Updated Body"}`,
+		`{"body": "Updated Body", "status": false}`,
+		`{"title": "No", "body": "Updated Body", "status": false}`,
+	} {
+		w := sendJSON(r, "PUT", target, "application/json", payload)
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+	}
+
+	stored, err := initializers.TodoRepository.FindByID(testToDo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Original Title", stored.Title)
+	assert.True(t, stored.Status)
+
+	w := sendJSON(r, "PUT", target, "application/json", `{"title": "Updated Title", "body": "", "status": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stored, err = initializers.TodoRepository.FindByID(testToDo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated Title", stored.Title)
+	assert.Equal(t, "", stored.Body)
+	assert.False(t, stored.Status)
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"example/Studying/controllers"
 	"example/Studying/initializers"
+	"example/Studying/jwt"
 	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"example/Studying/storage"
 	"fmt"
 	"log"
 	"net/http"
@@ -22,6 +26,54 @@ import (
 
 var DB *gorm.DB
 
+// testUser owns the todos the tests create; testToken is its access token.
+var (
+	testUser  *models.User
+	testToken string
+)
+
+// newRouter returns a router whose requests are made as testUser unless
+// they carry an Authorization header of their own.
+func newRouter() *gin.Engine {
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+testToken)
+		}
+	}, controllers.Authenticate)
+
+	return r
+}
+
+// decode unmarshals the body of w into a T, such as map[string]models.ToDo
+// for a {"todo": ...} response.
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	var response T
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	return response
+}
+
+// ownTodos is the todo repository as testUser sees it.
+func ownTodos() repositories.TodoRepository {
+	return repositories.NewOwnedTodoRepository(initializers.TodoRepository, testUser.ID)
+}
+
+// logIn registers a user and returns a session token for it.
+func logIn(username string) (*models.User, string, error) {
+	auth := services.NewAuthService(initializers.UserRepository, initializers.Auth)
+	if _, err := auth.Register(username, username+"@example.com", "password"); err != nil {
+		return nil, "", err
+	}
+
+	tokens, err := auth.Login(username, "password")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tokens.User, tokens.AccessToken, nil
+}
+
 func ConnectToDB() {
 	var err error
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
@@ -39,11 +91,7 @@ func ConnectToDB() {
 	}
 }
 
-func InitializeTestDB(db *gorm.DB) error {
-	if err := db.AutoMigrate(&models.ToDo{}); err != nil {
-		return err
-	}
-
+func InitializeTestDB(repo repositories.TodoRepository) error {
 	testTodos := []models.ToDo{
 		{Title: "Test ToDo 1", Body: "Test Body 1", Status: true},
 		{Title: "Test ToDo 2", Body: "Test Body 2", Status: false},
@@ -56,8 +104,8 @@ func InitializeTestDB(db *gorm.DB) error {
 	}
 
 	for _, todo := range testTodos {
-		if result := db.Create(&todo); result.Error != nil {
-			return result.Error
+		if err := repo.Create(&todo); err != nil {
+			return err
 		}
 	}
 
@@ -65,7 +113,24 @@ func InitializeTestDB(db *gorm.DB) error {
 }
 
 func ClearTestDB(db *gorm.DB) {
-	db.Exec("TRUNCATE TABLE to_dos RESTART IDENTITY CASCADE")
+	if db.Dialector.Name() == "sqlite" {
+		db.Exec("DELETE FROM subtasks")
+		db.Exec("DELETE FROM comments")
+		db.Exec("DELETE FROM attachments")
+		db.Exec("DELETE FROM todo_tags")
+		db.Exec("DELETE FROM tags")
+		db.Exec("DELETE FROM to_dos")
+		db.Exec("DELETE FROM projects")
+		db.Exec("DELETE FROM series")
+		db.Exec("DELETE FROM shares")
+		db.Exec("DELETE FROM sessions")
+		db.Exec("DELETE FROM api_keys")
+		db.Exec("DELETE FROM users")
+		db.Exec("DELETE FROM sqlite_sequence WHERE name IN ('to_dos', 'tags', 'subtasks', 'comments', 'attachments', 'projects', 'series', 'shares', 'sessions', 'api_keys', 'users')")
+		return
+	}
+
+	db.Exec("TRUNCATE TABLE subtasks, comments, attachments, todo_tags, tags, to_dos, projects, series, shares, sessions, api_keys, users RESTART IDENTITY CASCADE")
 }
 
 func TestMain(m *testing.M) {
@@ -75,24 +140,61 @@ func TestMain(m *testing.M) {
 		}
 	}
 
-	ConnectToDB()
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "postgres":
+		ConnectToDB()
+		initializers.DB = DB
+	case "sqlite":
+		if os.Getenv("DB_PATH") == "" {
+			os.Setenv("DB_PATH", ":memory:")
+		}
+		initializers.ConnectToDB()
+		DB = initializers.DB
+	case "memory":
+		initializers.InitMemoryRepositories()
+	default:
+		log.Fatalf("Unknown DB_DRIVER %q", driver)
+	}
 
-	if err := InitializeTestDB(DB); err != nil {
-		log.Fatalf("Failed to initialize test todo: %s", err)
+	if DB != nil {
+		if err := initializers.MigrateDB(); err != nil {
+			log.Fatalf("Failed to migrate test database: %s", err)
+		}
+		initializers.InitRepositories()
 	}
 
-	initializers.DB = DB
+	blobDir, err := os.MkdirTemp("", "todo-blobs-")
+	if err != nil {
+		log.Fatalf("Failed to create blob directory: %s", err)
+	}
+	if initializers.BlobStore, err = storage.NewLocalStore(blobDir); err != nil {
+		log.Fatalf("Failed to open blob directory: %s", err)
+	}
+
+	secret := []byte("test secret that is long enough!")
+	initializers.Auth.Signer = jwt.NewHS256Signer(secret)
+	initializers.Auth.Verifier = jwt.NewVerifier(secret)
+	if testUser, testToken, err = logIn("tester"); err != nil {
+		log.Fatalf("Failed to create test user: %s", err)
+	}
+
+	if err := InitializeTestDB(ownTodos()); err != nil {
+		log.Fatalf("Failed to initialize test todo: %s", err)
+	}
 
 	code := m.Run()
 
-	ClearTestDB(DB)
+	if DB != nil {
+		ClearTestDB(DB)
+	}
+	os.RemoveAll(blobDir)
 
 	os.Exit(code)
 }
 
 func TestToDoIndexWithoutStatus(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.GET("/todos", func(c *gin.Context) {
 		controllers.ToDoIndex(c)
@@ -114,7 +216,7 @@ func TestToDoIndexWithoutStatus(t *testing.T) {
 
 func TestToDoIndexWithStatus(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.GET("/todos", func(c *gin.Context) {
 		controllers.ToDoIndex(c)
@@ -140,7 +242,7 @@ func TestToDoIndexWithStatus(t *testing.T) {
 
 func TestToDoCreate(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.POST("/todos", func(c *gin.Context) {
 		controllers.ToDoCreate(c)
@@ -173,7 +275,7 @@ func TestToDoCreate(t *testing.T) {
 
 func TestToDoCreateWrongData(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.POST("/todos", func(c *gin.Context) {
 		controllers.ToDoCreate(c)
@@ -196,12 +298,12 @@ func TestToDoCreateWrongData(t *testing.T) {
 
 func TestToDoShow(t *testing.T) {
 	testToDo := models.ToDo{Title: "Test ToDo", Body: "Test Body", Status: true}
-	if result := initializers.DB.Create(&testToDo); result.Error != nil {
-		t.Fatalf("Failed to initialize test todo: %s", result.Error)
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
 	}
 
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.GET("/todos/:id", func(c *gin.Context) {
 		controllers.ToDoShow(c)
@@ -233,12 +335,12 @@ func TestToDoShow(t *testing.T) {
 
 func TestToDoUpdate(t *testing.T) {
 	testToDo := models.ToDo{Title: "Original Title", Body: "Original Body", Status: true}
-	if result := initializers.DB.Create(&testToDo); result.Error != nil {
-		t.Fatalf("Failed to initialize test todo: %s", result.Error)
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
 	}
 
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.PUT("/todos/:id", func(c *gin.Context) {
 		controllers.ToDoUpdate(c)
@@ -278,12 +380,12 @@ func TestToDoUpdate(t *testing.T) {
 
 func TestToDoDelete(t *testing.T) {
 	testToDo := models.ToDo{Title: "Test ToDo", Body: "Test Body", Status: true}
-	if result := initializers.DB.Create(&testToDo); result.Error != nil {
-		t.Fatalf("Failed to initialize test todo: %s", result.Error)
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
 	}
 
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := newRouter()
 
 	r.DELETE("/todos/:id", func(c *gin.Context) {
 		controllers.ToDoDelete(c)
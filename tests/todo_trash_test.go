@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/repositories"
+	"example/Studying/services"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func todoIDs(todos []models.ToDo) []uint {
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	return ids
+}
+
+func TestToDoTrash(t *testing.T) {
+	testToDo := models.ToDo{Title: "Trashed Title", Body: "Trashed Body", Status: false}
+	if err := ownTodos().Create(&testToDo); err != nil {
+		t.Fatalf("Failed to initialize test todo: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.GET("/todos/trash", func(c *gin.Context) {
+		controllers.ToDoTrash(c)
+	})
+	r.GET("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoShow(c)
+	})
+	r.POST("/todos/:id/restore", func(c *gin.Context) {
+		controllers.ToDoRestore(c)
+	})
+	r.DELETE("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoDelete(c)
+	})
+
+	target := fmt.Sprintf("/todos/%d", testToDo.ID)
+
+	// Restoring a live todo is not possible
+	w := sendWithHeader(r, "POST", target+"/restore", "", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = sendWithHeader(r, "DELETE", target, "", "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w, page := getPage(t, r, "/todos/trash?limit=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, todoIDs(page.Todos), testToDo.ID)
+	for _, todo := range page.Todos {
+		assert.True(t, todo.DeletedAt.Valid)
+	}
+
+	w = sendWithHeader(r, "POST", target+"/restore", "", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	var response map[string]models.ToDo
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Trashed Title", response["todo"].Title)
+	assert.Equal(t, uint(2), response["todo"].Version)
+
+	w, page = getPage(t, r, "/todos/trash?limit=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, todoIDs(page.Todos), testToDo.ID)
+
+	w = sendWithHeader(r, "GET", target, "", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestToDoHardDelete(t *testing.T) {
+	live := models.ToDo{Title: "Purged Title", Body: "Purged Body", Status: false}
+	trashed := models.ToDo{Title: "Purged Trash", Body: "Purged Body", Status: false}
+	for _, todo := range []*models.ToDo{&live, &trashed} {
+		if err := ownTodos().Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	if err := initializers.TodoRepository.Delete(trashed.ID, 0); err != nil {
+		t.Fatalf("Failed to trash test todo: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos/:id/restore", func(c *gin.Context) {
+		controllers.ToDoRestore(c)
+	})
+	r.DELETE("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoDelete(c)
+	})
+
+	w := sendWithHeader(r, "DELETE", fmt.Sprintf("/todos/%d?hard=maybe", live.ID), "", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// A stale version keeps the todo
+	w = sendWithHeader(r, "DELETE", fmt.Sprintf("/todos/%d?hard=true", live.ID), "", "If-Match", `"0-0"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = sendWithHeader(r, "DELETE", fmt.Sprintf("/todos/%d?hard=true", live.ID), "", "If-Match", fmt.Sprintf(`"%d-1"`, live.ID))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = sendWithHeader(r, "DELETE", fmt.Sprintf("/todos/%d?hard=true", trashed.ID), "", "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, id := range []uint{live.ID, trashed.ID} {
+		_, err := initializers.TodoRepository.FindByIDUnscoped(id)
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+		w = sendWithHeader(r, "POST", fmt.Sprintf("/todos/%d/restore", id), "", "", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+
+	w = sendWithHeader(r, "DELETE", fmt.Sprintf("/todos/%d?hard=true", live.ID), "", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	repo := repositories.NewMemoryTodoRepository()
+	todoService := services.NewTodoService(repo)
+
+	kept := models.ToDo{Title: "Kept Title", Body: "Kept Body"}
+	trashed := models.ToDo{Title: "Trashed Title", Body: "Trashed Body"}
+	for _, todo := range []*models.ToDo{&kept, &trashed} {
+		if err := repo.Create(todo); err != nil {
+			t.Fatalf("Failed to initialize test todo: %s", err)
+		}
+	}
+	assert.NoError(t, repo.Delete(trashed.ID, 0))
+
+	// Nothing has been in the trash for an hour yet
+	purged, err := todoService.PurgeTrash(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = todoService.PurgeTrash(-time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = repo.FindByIDUnscoped(trashed.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	_, err = repo.FindByID(kept.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"example/Studying/controllers"
+	"example/Studying/initializers"
+	"example/Studying/models"
+	"example/Studying/services"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	workflow, err := services.ParseWorkflow("todo>in_progress;in_progress>done, todo ; done>backlog")
+	assert.Error(t, err)
+	assert.Nil(t, workflow)
+
+	workflow, err = services.ParseWorkflow("backlog>in_progress;in_progress>done,backlog;done>backlog")
+	assert.NoError(t, err)
+	assert.Equal(t, models.StateBacklog, workflow.Initial)
+	assert.True(t, workflow.Allows(models.StateBacklog, models.StateInProgress))
+	assert.True(t, workflow.Allows(models.StateInProgress, models.StateDone))
+	assert.True(t, workflow.Allows(models.StateDone, models.StateDone))
+	assert.False(t, workflow.Allows(models.StateBacklog, models.StateDone))
+	assert.False(t, workflow.Allows(models.StateBlocked, models.StateBacklog))
+
+	for _, spec := range []string{"", "backlog", "backlog>later"} {
+		_, err := services.ParseWorkflow(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestToDoWorkflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos", func(c *gin.Context) {
+		controllers.ToDoCreate(c)
+	})
+	r.GET("/todos", func(c *gin.Context) {
+		controllers.ToDoIndex(c)
+	})
+	r.PUT("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoUpdate(c)
+	})
+	r.PATCH("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoPatch(c)
+	})
+
+	w := sendJSON(r, "POST", "/todos", "application/json", `{"title": "Write report", "state": "someday"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "POST", "/todos", "application/json", `{"title": "Write report"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	todo := decode[map[string]models.ToDo](t, w)["todo"]
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+	assert.Equal(t, models.StateBacklog, todo.State)
+	assert.False(t, todo.Status)
+	assert.Nil(t, todo.CompletedAt)
+	target := fmt.Sprintf("/todos/%d", todo.ID)
+
+	w = sendJSON(r, "POST", "/todos", "application/json", `{"title": "Already done", "status": true}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	done := decode[map[string]models.ToDo](t, w)["todo"]
+	defer initializers.TodoRepository.Purge(done.ID, 0)
+	assert.Equal(t, models.StateDone, done.State)
+	assert.NotNil(t, done.CompletedAt)
+
+	// Moving through the workflow
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "in_progress"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "blocked"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.StateBlocked, decode[map[string]models.ToDo](t, w)["todo"].State)
+
+	// Illegal moves are rejected and change nothing
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "done", "title": "Report written"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "finished"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "in_progress"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	updated := decode[map[string]models.ToDo](t, w)["todo"]
+	assert.Equal(t, "Write report", updated.Title)
+	assert.Equal(t, models.StateInProgress, updated.State)
+
+	// Completing records when, reopening forgets it
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "in_review"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "done"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	updated = decode[map[string]models.ToDo](t, w)["todo"]
+	assert.True(t, updated.Status)
+	assert.NotNil(t, updated.CompletedAt)
+
+	w = sendJSON(r, "PUT", target, "application/json", `{"title": "Write report", "body": "", "status": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	updated = decode[map[string]models.ToDo](t, w)["todo"]
+	assert.Equal(t, models.StateBacklog, updated.State)
+	assert.Nil(t, updated.CompletedAt)
+
+	w = sendJSON(r, "PUT", target, "application/json", `{"title": "Write report", "body": "", "status": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.StateDone, decode[map[string]models.ToDo](t, w)["todo"].State)
+
+	w = sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "cancelled"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Both filters see the state
+	w, page := getPage(t, r, "/todos?status=true&limit=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, todoIDs(page.Todos), todo.ID)
+
+	w, page = getPage(t, r, "/todos?state=backlog,done&limit=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, todoIDs(page.Todos), todo.ID)
+	assert.Contains(t, todoIDs(page.Todos), done.ID)
+
+	w, page = getPage(t, r, "/todos?state=in_progress&limit=100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, todoIDs(page.Todos), todo.ID)
+
+	w, page = getPage(t, r, `/todos?limit=100&filter=state+%3D+%22done%22`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, todoIDs(page.Todos), todo.ID)
+
+	w, _ = getPage(t, r, "/todos?state=someday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestToDoCustomWorkflow(t *testing.T) {
+	workflow, err := services.ParseWorkflow("backlog>in_progress;in_progress>done")
+	assert.NoError(t, err)
+	defer func(previous *services.Workflow) { initializers.Workflow = previous }(initializers.Workflow)
+	initializers.Workflow = workflow
+
+	gin.SetMode(gin.TestMode)
+	r := newRouter()
+
+	r.POST("/todos", func(c *gin.Context) {
+		controllers.ToDoCreate(c)
+	})
+	r.PATCH("/todos/:id", func(c *gin.Context) {
+		controllers.ToDoPatch(c)
+	})
+
+	w := sendJSON(r, "POST", "/todos", "application/json", `{"title": "Ship it"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	todo := decode[map[string]models.ToDo](t, w)["todo"]
+	defer initializers.TodoRepository.Purge(todo.ID, 0)
+	target := fmt.Sprintf("/todos/%d", todo.ID)
+
+	assert.Equal(t, http.StatusConflict, sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`).Code)
+	assert.Equal(t, http.StatusOK, sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"state": "in_progress"}`).Code)
+	assert.Equal(t, http.StatusOK, sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": true}`).Code)
+	assert.Equal(t, http.StatusConflict, sendJSON(r, "PATCH", target, "application/merge-patch+json", `{"status": false}`).Code)
+}